@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/YilinZhang0101/SwiftScheduler/internal/scheduler"
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// adminServer implements the operator-facing AdminService
+type adminServer struct {
+	pb.UnimplementedAdminServiceServer
+	stateManager *scheduler.StateManager // dependency injection
+	dispatcher   *scheduler.Dispatcher
+}
+
+// DrainWorker stops new assignments to a worker and tells it to finish its
+// in-flight tasks, deregister and exit
+func (s *adminServer) DrainWorker(ctx context.Context, req *pb.DrainWorkerRequest) (*pb.DrainWorkerResponse, error) {
+	inFlight, err := s.stateManager.SetDraining(req.WorkerId)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = "drain requested by operator"
+	}
+	msg := &pb.MasterMessage{
+		Payload: &pb.MasterMessage_Drain{
+			Drain: &pb.Drain{
+				Reason:        reason,
+				TimeoutMillis: req.TimeoutMillis,
+			},
+		},
+	}
+	if err := s.stateManager.SendToWorker(req.WorkerId, msg); err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to send drain to worker %s: %v", req.WorkerId, err)
+	}
+
+	log.Printf("Draining worker %s (%d tasks in flight): %s", req.WorkerId, inFlight, reason)
+	return &pb.DrainWorkerResponse{InFlight: int32(inFlight)}, nil
+}
+
+// ListDeadLetters returns the dead-lettered tasks matching the request, oldest first
+func (s *adminServer) ListDeadLetters(ctx context.Context, req *pb.ListDeadLettersRequest) (*pb.ListDeadLettersResponse, error) {
+	letters := s.dispatcher.DeadLetters().List(scheduler.TaskFilter{
+		Name:   req.TaskName,
+		Labels: req.Labels,
+		Limit:  int(req.Limit),
+	})
+
+	resp := &pb.ListDeadLettersResponse{
+		DeadLetters: make([]*pb.DeadLetter, 0, len(letters)),
+	}
+	for _, l := range letters {
+		resp.DeadLetters = append(resp.DeadLetters, toDeadLetter(l))
+	}
+	return resp, nil
+}
+
+// GetDeadLetter returns one dead-lettered task with its failure history
+func (s *adminServer) GetDeadLetter(ctx context.Context, req *pb.GetDeadLetterRequest) (*pb.DeadLetter, error) {
+	l, ok := s.dispatcher.DeadLetters().Get(req.TaskId)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no dead letter for task %s", req.TaskId)
+	}
+	return toDeadLetter(l), nil
+}
+
+// ReplayDeadLetter submits a dead-lettered task again as a new task
+func (s *adminServer) ReplayDeadLetter(ctx context.Context, req *pb.ReplayDeadLetterRequest) (*pb.ReplayDeadLetterResponse, error) {
+	id, err := s.dispatcher.Replay(req.TaskId, req.NewTaskId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.ReplayDeadLetterResponse{TaskId: id}, nil
+}
+
+// toDeadLetter converts a dead letter to the wire format
+func toDeadLetter(l scheduler.DeadLetter) *pb.DeadLetter {
+	out := &pb.DeadLetter{
+		Task:           toTaskInfo(l.Task),
+		Assignment:     l.Assignment,
+		Failures:       make([]*pb.TaskFailure, 0, len(l.Failures)),
+		Reason:         l.Reason,
+		DeadAtUnixNano: unixNano(l.DeadAt),
+	}
+	for _, f := range l.Failures {
+		out.Failures = append(out.Failures, &pb.TaskFailure{
+			Attempt:    f.Attempt,
+			WorkerId:   f.WorkerID,
+			Error:      f.Error,
+			ErrorClass: f.Class,
+			AtUnixNano: unixNano(f.At),
+		})
+	}
+	return out
+}
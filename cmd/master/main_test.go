@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/YilinZhang0101/SwiftScheduler/internal/keepalive"
+	"github.com/YilinZhang0101/SwiftScheduler/internal/scheduler"
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+// fakeConnectStream is a worker's Connect stream fed from a channel; closing
+// recv ends it with io.EOF
+type fakeConnectStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	recv chan *pb.WorkerMessage
+	err  chan error
+}
+
+func newFakeConnectStream(t *testing.T) *fakeConnectStream {
+	ctx, cancel := context.WithCancel(peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 50000},
+	}))
+	t.Cleanup(cancel)
+	return &fakeConnectStream{
+		ctx:  ctx,
+		recv: make(chan *pb.WorkerMessage, 8),
+		err:  make(chan error, 1),
+	}
+}
+
+func (s *fakeConnectStream) Context() context.Context { return s.ctx }
+
+func (s *fakeConnectStream) Send(*pb.MasterMessage) error { return nil }
+
+func (s *fakeConnectStream) Recv() (*pb.WorkerMessage, error) {
+	select {
+	case msg, ok := <-s.recv:
+		if !ok {
+			return nil, io.EOF
+		}
+		return msg, nil
+	case err := <-s.err:
+		return nil, err
+	}
+}
+
+func TestConnectForgetsWorker(t *testing.T) {
+	tests := []struct {
+		name      string
+		end       func(s *masterServer, stream *fakeConnectStream)
+		forgotten bool
+	}{
+		{"disconnected", func(_ *masterServer, stream *fakeConnectStream) { close(stream.recv) }, true},
+		{"connection lost", func(_ *masterServer, stream *fakeConnectStream) { stream.err <- errors.New("connection reset") }, true},
+		{"deregistered", func(_ *masterServer, stream *fakeConnectStream) {
+			stream.recv <- &pb.WorkerMessage{WorkerId: "w1", Payload: &pb.WorkerMessage_Deregister{Deregister: &pb.Deregister{Reason: "shutdown"}}}
+		}, true},
+		// the new session keeps the statistics of the worker
+		{"replaced by a new session", func(s *masterServer, _ *fakeConnectStream) {
+			s.stateManager.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4}, "w1", newFakeConnectStream(t), nil)
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := scheduler.NewStateManager()
+			s := &masterServer{
+				stateManager: sm,
+				dispatcher:   scheduler.NewDispatcher(sm),
+				keepalive:    keepalive.NewController(keepalive.DefaultConfig()),
+			}
+			stream := newFakeConnectStream(t)
+			stream.recv <- &pb.WorkerMessage{WorkerId: "w1", Payload: &pb.WorkerMessage_RegisterRequest{RegisterRequest: &pb.RegisterRequest{MaxConcurrency: 4}}}
+			done := make(chan error, 1)
+			go func() { done <- s.Connect(stream) }()
+
+			for deadline := time.Now().Add(5 * time.Second); !sm.Registered("w1"); time.Sleep(5 * time.Millisecond) {
+				if time.Now().After(deadline) {
+					t.Fatal("worker was not registered")
+				}
+			}
+			s.keepalive.ObserveRTT("w1", 10*time.Millisecond)
+
+			tt.end(s, stream)
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Connect did not return")
+			}
+			if rtt, _, _ := s.keepalive.Stats("w1"); (rtt == 0) != tt.forgotten {
+				t.Errorf("RTT mean after the session = %v, want the worker forgotten: %v", rtt, tt.forgotten)
+			}
+		})
+	}
+}
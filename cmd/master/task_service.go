@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/YilinZhang0101/SwiftScheduler/internal/scheduler"
+	"github.com/YilinZhang0101/SwiftScheduler/internal/source"
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// taskServer implements the client-facing TaskService on top of the Dispatcher
+type taskServer struct {
+	pb.UnimplementedTaskServiceServer
+	dispatcher *scheduler.Dispatcher // dependency injection
+	source     *source.GRPCSource    // submitted tasks enter the Dispatcher through it
+}
+
+// SubmitTask queues one task and returns its ID, or the ID and state of the
+// task submitted earlier with the same idempotency key
+func (s *taskServer) SubmitTask(ctx context.Context, req *pb.SubmitTaskRequest) (*pb.SubmitTaskResponse, error) {
+	return s.submit(ctx, req)
+}
+
+// SubmitBatch queues several tasks; a rejected task does not stop the others
+func (s *taskServer) SubmitBatch(ctx context.Context, req *pb.SubmitBatchRequest) (*pb.SubmitBatchResponse, error) {
+	resp := &pb.SubmitBatchResponse{
+		Results: make([]*pb.SubmitBatchItem, 0, len(req.Tasks)),
+	}
+	for _, t := range req.Tasks {
+		r, err := s.submit(ctx, t)
+		item := &pb.SubmitBatchItem{TaskId: t.TaskId}
+		if err != nil {
+			item.Error = status.Convert(err).Message()
+		} else {
+			item.TaskId = r.TaskId
+			item.Duplicate = r.Duplicate
+			item.State = r.State
+		}
+		resp.Results = append(resp.Results, item)
+	}
+	return resp, nil
+}
+
+// GetTaskStatus returns the current state of a task
+func (s *taskServer) GetTaskStatus(ctx context.Context, req *pb.GetTaskStatusRequest) (*pb.TaskInfo, error) {
+	t, ok := s.dispatcher.Tasks().GetTask(req.TaskId)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %s not found", req.TaskId)
+	}
+	return toTaskInfo(t), nil
+}
+
+// WaitForTask streams the task's state on every change and returns once it
+// reaches a terminal state
+func (s *taskServer) WaitForTask(req *pb.WaitForTaskRequest, stream grpc.ServerStreamingServer[pb.TaskInfo]) error {
+	// 1. subscribe first so no transition is missed between the snapshot and the watch
+	updates, stop, err := s.dispatcher.Tasks().Watch(req.TaskId)
+	if err != nil {
+		return toStatus(err)
+	}
+	defer stop()
+
+	// 2. send the current state
+	t, _ := s.dispatcher.Tasks().GetTask(req.TaskId)
+	if err := stream.Send(toTaskInfo(t)); err != nil {
+		return err
+	}
+
+	// 3. follow changes until terminal
+	for !t.State.Terminal() {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case t = <-updates:
+			if err := stream.Send(toTaskInfo(t)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CancelTask cancels a task; a task already on a worker is stopped there
+func (s *taskServer) CancelTask(ctx context.Context, req *pb.CancelTaskRequest) (*pb.CancelTaskResponse, error) {
+	t, err := s.dispatcher.Cancel(req.TaskId, req.Reason)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CancelTaskResponse{Task: toTaskInfo(t)}, nil
+}
+
+// CancelTasks cancels every unfinished task matching the request's filter
+func (s *taskServer) CancelTasks(ctx context.Context, req *pb.CancelTasksRequest) (*pb.CancelTasksResponse, error) {
+	if len(req.Labels) == 0 && req.TaskName == "" && req.WorkerId == "" {
+		// an empty filter would cancel everything
+		return nil, status.Error(codes.InvalidArgument, "at least one of labels, task_name and worker_id is required")
+	}
+	cancelled := s.dispatcher.CancelMatching(scheduler.TaskFilter{
+		Labels:   req.Labels,
+		Name:     req.TaskName,
+		WorkerID: req.WorkerId,
+	}, req.Reason)
+
+	resp := &pb.CancelTasksResponse{
+		Tasks: make([]*pb.TaskInfo, 0, len(cancelled)),
+	}
+	for _, t := range cancelled {
+		resp.Tasks = append(resp.Tasks, toTaskInfo(t))
+	}
+	return resp, nil
+}
+
+// submit validates a request and hands it to the Dispatcher via the API source
+func (s *taskServer) submit(ctx context.Context, req *pb.SubmitTaskRequest) (*pb.SubmitTaskResponse, error) {
+	if req.TaskName == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_name is required")
+	}
+	id := req.TaskId
+	if id == "" {
+		id = scheduler.NewTaskID()
+	}
+
+	task := &pb.TaskAssignment{
+		TaskId:         id,
+		TaskName:       req.TaskName,
+		TaskPayload:    req.TaskPayload,
+		Placement:      req.Placement,
+		Requests:       req.Requests,
+		Labels:         req.Labels,
+		TimeoutMillis:  req.TimeoutMillis,
+		RetryPolicy:    req.RetryPolicy,
+		IdempotencyKey: req.IdempotencyKey,
+	}
+	if err := s.source.Submit(ctx, task); err != nil {
+		if errors.Is(err, scheduler.ErrDuplicateTask) {
+			// a producer retrying a submission gets the original task
+			if t, ok := s.dispatcher.Tasks().FindByKey(req.IdempotencyKey); ok {
+				return &pb.SubmitTaskResponse{TaskId: t.ID, Duplicate: true, State: taskStates[t.State]}, nil
+			}
+		}
+		return nil, toStatus(err)
+	}
+	return &pb.SubmitTaskResponse{TaskId: id}, nil
+}
+
+// toStatus maps scheduler errors to gRPC status codes
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, scheduler.ErrTaskNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, scheduler.ErrTaskExists), errors.Is(err, scheduler.ErrDuplicateTask):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, scheduler.ErrInvalidPlacement), errors.Is(err, scheduler.ErrInvalidResources),
+		errors.Is(err, scheduler.ErrInvalidTimeout), errors.Is(err, scheduler.ErrInvalidRetryPolicy):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, scheduler.ErrIllegalTransition):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// taskStates maps scheduler task states to their protobuf equivalents
+var taskStates = map[scheduler.TaskState]pb.TaskState{
+	scheduler.TaskPending:   pb.TaskState_TASK_STATE_PENDING,
+	scheduler.TaskAssigned:  pb.TaskState_TASK_STATE_ASSIGNED,
+	scheduler.TaskRunning:   pb.TaskState_TASK_STATE_RUNNING,
+	scheduler.TaskSucceeded: pb.TaskState_TASK_STATE_SUCCEEDED,
+	scheduler.TaskFailed:    pb.TaskState_TASK_STATE_FAILED,
+	scheduler.TaskCancelled: pb.TaskState_TASK_STATE_CANCELLED,
+	scheduler.TaskTimedOut:  pb.TaskState_TASK_STATE_TIMED_OUT,
+}
+
+// toTaskInfo converts the master's task record to the wire format
+func toTaskInfo(t scheduler.Task) *pb.TaskInfo {
+	return &pb.TaskInfo{
+		TaskId:             t.ID,
+		TaskName:           t.Name,
+		State:              taskStates[t.State],
+		WorkerId:           t.WorkerID,
+		Attempts:           t.Attempts,
+		CreatedAtUnixNano:  unixNano(t.CreatedAt),
+		AssignedAtUnixNano: unixNano(t.AssignedAt),
+		StartedAtUnixNano:  unixNano(t.StartedAt),
+		FinishedAtUnixNano: unixNano(t.FinishedAt),
+		LastError:          t.LastError,
+		Output:             t.Output,
+		Labels:             t.Labels,
+		NotBeforeUnixNano:  unixNano(t.NotBefore),
+		FencingToken:       t.FencingToken,
+		IdempotencyKey:     t.IdempotencyKey,
+	}
+}
+
+// unixNano returns 0 for unset times instead of a large negative number
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
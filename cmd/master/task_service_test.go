@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/YilinZhang0101/SwiftScheduler/internal/scheduler"
+	"github.com/YilinZhang0101/SwiftScheduler/internal/source"
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestTaskServer returns a taskServer whose API source feeds a Dispatcher.
+// No worker is registered, so submitted tasks stay Pending.
+func newTestTaskServer(t *testing.T) *taskServer {
+	t.Helper()
+	d := scheduler.NewDispatcher(scheduler.NewStateManager())
+	src := source.NewGRPCSource()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go d.Consume(ctx, src)
+	return &taskServer{dispatcher: d, source: src}
+}
+
+// fakeWaitStream is a WaitForTask stream recording what the server sends
+type fakeWaitStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan *pb.TaskInfo
+}
+
+func (s *fakeWaitStream) Context() context.Context { return s.ctx }
+
+func (s *fakeWaitStream) Send(info *pb.TaskInfo) error {
+	s.sent <- info
+	return nil
+}
+
+// next waits for the next TaskInfo sent on the stream
+func (s *fakeWaitStream) next(t *testing.T) *pb.TaskInfo {
+	t.Helper()
+	select {
+	case info := <-s.sent:
+		return info
+	case <-time.After(5 * time.Second):
+		t.Fatal("no task state sent")
+		return nil
+	}
+}
+
+func TestSubmitTask(t *testing.T) {
+	s := newTestTaskServer(t)
+	ctx := context.Background()
+
+	resp, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskName: "resize"})
+	if err != nil || resp.TaskId == "" || resp.Duplicate {
+		t.Fatalf("SubmitTask() = %v, %v; want a new task ID", resp, err)
+	}
+	info, err := s.GetTaskStatus(ctx, &pb.GetTaskStatusRequest{TaskId: resp.TaskId})
+	if err != nil || info.State != pb.TaskState_TASK_STATE_PENDING || info.TaskName != "resize" {
+		t.Errorf("GetTaskStatus() = %v, %v; want a pending resize task", info, err)
+	}
+
+	tests := []struct {
+		name string
+		req  *pb.SubmitTaskRequest
+		code codes.Code
+	}{
+		{"no task name", &pb.SubmitTaskRequest{TaskId: "t2"}, codes.InvalidArgument},
+		{"negative timeout", &pb.SubmitTaskRequest{TaskId: "t3", TaskName: "resize", TimeoutMillis: -1}, codes.InvalidArgument},
+		{"task ID taken", &pb.SubmitTaskRequest{TaskId: resp.TaskId, TaskName: "resize"}, codes.AlreadyExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.SubmitTask(ctx, tt.req); status.Code(err) != tt.code {
+				t.Errorf("SubmitTask() = %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestSubmitTaskDuplicateKey(t *testing.T) {
+	s := newTestTaskServer(t)
+	ctx := context.Background()
+
+	first, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskId: "t1", TaskName: "resize", IdempotencyKey: "k1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a producer retrying with a fresh task ID gets the original task
+	dup, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskId: "t2", TaskName: "resize", IdempotencyKey: "k1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dup.Duplicate || dup.TaskId != first.TaskId || dup.State != pb.TaskState_TASK_STATE_PENDING {
+		t.Errorf("duplicate SubmitTask() = %v, want task %s marked duplicate and pending", dup, first.TaskId)
+	}
+	if _, err := s.GetTaskStatus(ctx, &pb.GetTaskStatusRequest{TaskId: "t2"}); status.Code(err) != codes.NotFound {
+		t.Errorf("duplicate was recorded as task t2: %v", err)
+	}
+}
+
+func TestSubmitBatch(t *testing.T) {
+	s := newTestTaskServer(t)
+	resp, err := s.SubmitBatch(context.Background(), &pb.SubmitBatchRequest{Tasks: []*pb.SubmitTaskRequest{
+		{TaskId: "t1", TaskName: "resize", IdempotencyKey: "k1"},
+		{TaskId: "t2"}, // no task name
+		{TaskId: "t3", TaskName: "resize", IdempotencyKey: "k1"},
+		{TaskId: "t4", TaskName: "resize"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		id        string
+		failed    bool
+		duplicate bool
+	}{
+		{"t1", false, false},
+		{"t2", true, false},
+		{"t1", false, true},
+		{"t4", false, false},
+	}
+	if len(resp.Results) != len(want) {
+		t.Fatalf("%d results, want %d", len(resp.Results), len(want))
+	}
+	for i, w := range want {
+		r := resp.Results[i]
+		if r.TaskId != w.id || (r.Error != "") != w.failed || r.Duplicate != w.duplicate {
+			t.Errorf("result %d = %v, want task %s failed %v duplicate %v", i, r, w.id, w.failed, w.duplicate)
+		}
+	}
+}
+
+func TestWaitForTask(t *testing.T) {
+	s := newTestTaskServer(t)
+	if _, err := s.SubmitTask(context.Background(), &pb.SubmitTaskRequest{TaskId: "t1", TaskName: "resize"}); err != nil {
+		t.Fatal(err)
+	}
+
+	stream := &fakeWaitStream{ctx: context.Background(), sent: make(chan *pb.TaskInfo, 8)}
+	done := make(chan error, 1)
+	go func() { done <- s.WaitForTask(&pb.WaitForTaskRequest{TaskId: "t1"}, stream) }()
+
+	if info := stream.next(t); info.State != pb.TaskState_TASK_STATE_PENDING {
+		t.Fatalf("first state = %s, want the current one, pending", info.State)
+	}
+	if _, err := s.CancelTask(context.Background(), &pb.CancelTaskRequest{TaskId: "t1"}); err != nil {
+		t.Fatal(err)
+	}
+	if info := stream.next(t); info.State != pb.TaskState_TASK_STATE_CANCELLED {
+		t.Errorf("state = %s, want cancelled", info.State)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("WaitForTask() = %v after a terminal state", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitForTask did not return after a terminal state")
+	}
+
+	if err := s.WaitForTask(&pb.WaitForTaskRequest{TaskId: "missing"}, stream); status.Code(err) != codes.NotFound {
+		t.Errorf("WaitForTask(missing) = %v, want NotFound", err)
+	}
+	// a cancelled task cannot be cancelled again
+	if _, err := s.CancelTask(context.Background(), &pb.CancelTaskRequest{TaskId: "t1"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("CancelTask() twice = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestWaitForTaskClientGone(t *testing.T) {
+	s := newTestTaskServer(t)
+	if _, err := s.SubmitTask(context.Background(), &pb.SubmitTaskRequest{TaskId: "t1", TaskName: "resize"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &fakeWaitStream{ctx: ctx, sent: make(chan *pb.TaskInfo, 8)}
+	done := make(chan error, 1)
+	go func() { done <- s.WaitForTask(&pb.WaitForTaskRequest{TaskId: "t1"}, stream) }()
+	stream.next(t)
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("WaitForTask() = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitForTask did not return after the client went away")
+	}
+}
+
+func TestCancelTasks(t *testing.T) {
+	s := newTestTaskServer(t)
+	ctx := context.Background()
+	for _, req := range []*pb.SubmitTaskRequest{
+		{TaskId: "t1", TaskName: "resize", Labels: map[string]string{"team": "ml"}},
+		{TaskId: "t2", TaskName: "resize", Labels: map[string]string{"team": "web"}},
+	} {
+		if _, err := s.SubmitTask(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// an empty filter would cancel everything
+	if _, err := s.CancelTasks(ctx, &pb.CancelTasksRequest{Reason: "cleanup"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CancelTasks() without a filter = %v, want InvalidArgument", err)
+	}
+	resp, err := s.CancelTasks(ctx, &pb.CancelTasksRequest{Labels: map[string]string{"team": "ml"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Tasks) != 1 || resp.Tasks[0].TaskId != "t1" || resp.Tasks[0].State != pb.TaskState_TASK_STATE_CANCELLED {
+		t.Errorf("cancelled %v, want t1 only", resp.Tasks)
+	}
+	if info, _ := s.GetTaskStatus(ctx, &pb.GetTaskStatusRequest{TaskId: "t2"}); info.State != pb.TaskState_TASK_STATE_PENDING {
+		t.Errorf("t2 is %s, want pending", info.State)
+	}
+}
@@ -3,6 +3,7 @@ module github.com/YilinZhang0101/SwiftScheduler
 go 1.24.3
 
 require (
+	github.com/rabbitmq/amqp091-go v1.10.0
 	google.golang.org/grpc v1.76.0
 	google.golang.org/protobuf v1.36.10
 )
@@ -0,0 +1,223 @@
+// Package keepalive implements the adaptive keepalive tuning described in
+// docs/design.md (section 2.2):
+//
+//	keepalive_time    = base_time    + α·RTT_mean + β·RTT_std
+//	keepalive_timeout = base_timeout + γ·RTT_mean + δ·FailRate·base_timeout
+//
+// RTT statistics and the failure rate are kept per worker over a sliding
+// window, so every connection gets parameters that match its own network
+// conditions.
+package keepalive
+
+import (
+	"sync"
+	"time"
+
+	"github.com/YilinZhang0101/SwiftScheduler/internal/ring"
+	gk "google.golang.org/grpc/keepalive"
+)
+
+// Config holds the tuning constants. Use DefaultConfig as a starting point.
+type Config struct {
+	BaseTime    time.Duration // base_time
+	BaseTimeout time.Duration // base_timeout
+
+	Alpha float64 // weight of RTT_mean in keepalive_time
+	Beta  float64 // weight of RTT_std in keepalive_time
+	Gamma float64 // weight of RTT_mean in keepalive_timeout
+	Delta float64 // weight of FailRate·base_timeout in keepalive_timeout
+
+	// WindowSize is the number of RTT samples and probe outcomes kept per worker
+	WindowSize int
+
+	// Computed values are clamped to these bounds
+	MinTime, MaxTime       time.Duration
+	MinTimeout, MaxTimeout time.Duration
+}
+
+// DefaultConfig returns constants that detect a silent worker within a few
+// seconds on a healthy LAN. BaseTime matches the worker heartbeat period.
+func DefaultConfig() Config {
+	return Config{
+		BaseTime:    5 * time.Second,
+		BaseTimeout: 2 * time.Second,
+		Alpha:       4,
+		Beta:        8,
+		Gamma:       4,
+		Delta:       1,
+		WindowSize:  32,
+		MinTime:     1 * time.Second,
+		MaxTime:     30 * time.Second,
+		MinTimeout:  500 * time.Millisecond,
+		MaxTimeout:  20 * time.Second,
+	}
+}
+
+// Params is the pair of keepalive parameters computed for one worker
+type Params struct {
+	Time    time.Duration // keepalive_time: interval between probes
+	Timeout time.Duration // keepalive_timeout: how long to wait for a probe answer
+}
+
+// Deadline is the longest silence tolerated before the worker is considered
+// dead: one probe interval plus the time allowed for the answer
+func (p Params) Deadline() time.Duration {
+	return p.Time + p.Timeout
+}
+
+// peerStats is the sliding-window state for one worker
+type peerStats struct {
+	rtt    *ring.Ring[float64] // RTT samples in seconds
+	probes *ring.Ring[float64] // 1 for a failed probe, 0 for a successful one
+}
+
+// Controller maintains per-worker statistics and computes keepalive Params.
+// It is a thread-safe component.
+type Controller struct {
+	cfg Config
+
+	mu    sync.Mutex
+	peers map[string]*peerStats // key is worker_id
+}
+
+// NewController constructs a Controller
+func NewController(cfg Config) *Controller {
+	if cfg.WindowSize <= 0 {
+		cfg.WindowSize = DefaultConfig().WindowSize
+	}
+	return &Controller{
+		cfg:   cfg,
+		peers: make(map[string]*peerStats),
+	}
+}
+
+// Config returns the tuning constants
+func (c *Controller) Config() Config {
+	return c.cfg
+}
+
+// peer returns the stats for a worker, creating them on first use.
+// Callers must hold c.mu.
+func (c *Controller) peer(workerID string) *peerStats {
+	ps, ok := c.peers[workerID]
+	if !ok {
+		ps = &peerStats{
+			rtt:    ring.New[float64](c.cfg.WindowSize),
+			probes: ring.New[float64](c.cfg.WindowSize),
+		}
+		c.peers[workerID] = ps
+	}
+	return ps
+}
+
+// ObserveRTT records a round-trip time sample for a worker
+func (c *Controller) ObserveRTT(workerID string, rtt time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.peer(workerID).rtt.Add(rtt.Seconds())
+}
+
+// ObserveProbe records whether a liveness probe to a worker was answered in time
+func (c *Controller) ObserveProbe(workerID string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v := 0.0
+	if !ok {
+		v = 1
+	}
+	c.peer(workerID).probes.Add(v)
+}
+
+// Stats returns the current RTT_mean, RTT_std and FailRate of a worker
+func (c *Controller) Stats(workerID string) (rttMean, rttStd time.Duration, failRate float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ps, ok := c.peers[workerID]
+	if !ok {
+		return 0, 0, 0
+	}
+	return seconds(ring.Mean(ps.rtt)), seconds(ring.Std(ps.rtt)), ring.Mean(ps.probes)
+}
+
+// Params computes keepalive_time and keepalive_timeout for a worker.
+// Workers without any samples get the base values.
+func (c *Controller) Params(workerID string) Params {
+	mean, std, failRate := c.Stats(workerID)
+	return c.compute(mean, std, failRate)
+}
+
+// Forget drops the statistics of a worker
+func (c *Controller) Forget(workerID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.peers, workerID)
+}
+
+// compute applies the tuning formulas and clamps the results
+func (c *Controller) compute(rttMean, rttStd time.Duration, failRate float64) Params {
+	cfg := c.cfg
+	t := cfg.BaseTime +
+		scale(rttMean, cfg.Alpha) +
+		scale(rttStd, cfg.Beta)
+	timeout := cfg.BaseTimeout +
+		scale(rttMean, cfg.Gamma) +
+		scale(cfg.BaseTimeout, cfg.Delta*failRate)
+
+	return Params{
+		Time:    clamp(t, cfg.MinTime, cfg.MaxTime),
+		Timeout: clamp(timeout, cfg.MinTimeout, cfg.MaxTimeout),
+	}
+}
+
+// ServerParameters returns the transport-level keepalive settings for the
+// master's grpc.Server, derived from the base values. gRPC fixes these per
+// server, so the per-worker adaptation happens at the application level
+// using Params.
+func (c *Controller) ServerParameters() gk.ServerParameters {
+	p := c.compute(0, 0, 0)
+	return gk.ServerParameters{
+		Time:    p.Time,
+		Timeout: p.Timeout,
+	}
+}
+
+// EnforcementPolicy lets workers send keepalive pings as often as MinTime
+// without the server closing the connection for "too many pings"
+func (c *Controller) EnforcementPolicy() gk.EnforcementPolicy {
+	return gk.EnforcementPolicy{
+		MinTime:             c.cfg.MinTime,
+		PermitWithoutStream: true,
+	}
+}
+
+// ClientParameters returns the transport-level keepalive settings for a
+// worker's connection to the master. Note that grpc-go raises client ping
+// intervals below 10s to 10s.
+func (c *Controller) ClientParameters() gk.ClientParameters {
+	p := c.compute(0, 0, 0)
+	return gk.ClientParameters{
+		Time:                p.Time,
+		Timeout:             p.Timeout,
+		PermitWithoutStream: true,
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+func scale(d time.Duration, k float64) time.Duration {
+	return time.Duration(float64(d) * k)
+}
+
+func clamp(d, lo, hi time.Duration) time.Duration {
+	if lo > 0 && d < lo {
+		return lo
+	}
+	if hi > 0 && d > hi {
+		return hi
+	}
+	return d
+}
@@ -0,0 +1,62 @@
+package keepalive
+
+import (
+	"testing"
+	"time"
+)
+
+func TestControllerParams(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name   string
+		rtts   []time.Duration
+		probes []bool
+		want   Params
+	}{
+		{"no samples", nil, nil, Params{5 * time.Second, 2 * time.Second}},
+		// time += 4·mean, timeout += 4·mean
+		{"steady rtt", []time.Duration{100 * ms, 100 * ms}, nil, Params{5400 * ms, 2400 * ms}},
+		// time += 4·mean + 8·std
+		{"jittery rtt", []time.Duration{100 * ms, 300 * ms}, nil, Params{6600 * ms, 2800 * ms}},
+		// timeout += 1·FailRate·base_timeout
+		{"lost probes", nil, []bool{true, false}, Params{5 * time.Second, 3 * time.Second}},
+		{"clamped", []time.Duration{10 * time.Second}, nil, Params{30 * time.Second, 20 * time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewController(DefaultConfig())
+			for _, rtt := range tt.rtts {
+				c.ObserveRTT("w1", rtt)
+			}
+			for _, ok := range tt.probes {
+				c.ObserveProbe("w1", ok)
+			}
+			got := c.Params("w1")
+			if (got.Time-tt.want.Time).Abs() > time.Microsecond || (got.Timeout-tt.want.Timeout).Abs() > time.Microsecond {
+				t.Errorf("Params() = %+v, want %+v", got, tt.want)
+			}
+			if d := got.Deadline(); d != got.Time+got.Timeout {
+				t.Errorf("Deadline() = %v, want %v", d, got.Time+got.Timeout)
+			}
+		})
+	}
+}
+
+func TestControllerWindow(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.WindowSize = 2
+	c := NewController(cfg)
+	for _, rtt := range []time.Duration{time.Second, 10 * time.Millisecond, 10 * time.Millisecond} {
+		c.ObserveRTT("w1", rtt)
+	}
+	c.ObserveRTT("w2", time.Second)
+
+	// the 1s sample of w1 left the window; w2 is kept apart
+	if mean, std, _ := c.Stats("w1"); (mean-10*time.Millisecond).Abs() > time.Microsecond || std > time.Microsecond {
+		t.Errorf("Stats(w1) = %v, %v; want 10ms, 0", mean, std)
+	}
+	c.Forget("w2")
+	if mean, _, _ := c.Stats("w2"); mean != 0 {
+		t.Errorf("Stats(w2) after Forget = %v, want 0", mean)
+	}
+}
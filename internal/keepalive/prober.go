@@ -0,0 +1,111 @@
+package keepalive
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// Prober measures RTT to one worker with application-level Ping/Pong over the
+// Connect stream (design doc section 5.1). Pings go out every keepalive_time
+// computed for that worker; a Pong that does not arrive within
+// keepalive_timeout counts as a failed probe.
+type Prober struct {
+	workerID string
+	ctrl     *Controller
+	send     func(*pb.MasterMessage) error
+	onRTT    func(time.Duration) // additional consumer of every sample, may be nil
+
+	mu          sync.Mutex
+	nonce       uint64
+	outstanding map[uint64]time.Time // nonce -> monotonic send time
+}
+
+// NewProber constructs a Prober for a worker. send writes to the worker's
+// stream; onRTT receives every RTT sample after the Controller has seen it.
+func NewProber(workerID string, ctrl *Controller, send func(*pb.MasterMessage) error, onRTT func(time.Duration)) *Prober {
+	return &Prober{
+		workerID:    workerID,
+		ctrl:        ctrl,
+		send:        send,
+		onRTT:       onRTT,
+		outstanding: make(map[uint64]time.Time),
+	}
+}
+
+// Run sends Pings until ctx is cancelled or a send fails
+func (p *Prober) Run(ctx context.Context) {
+	for {
+		params := p.ctrl.Params(p.workerID)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(params.Time):
+		}
+
+		p.expire(params.Timeout)
+		if err := p.ping(); err != nil {
+			log.Printf("[Prober] Failed to ping worker %s: %v", p.workerID, err)
+			return
+		}
+	}
+}
+
+// HandlePong turns a Pong into an RTT sample. Unknown or late nonces are ignored.
+func (p *Prober) HandlePong(pong *pb.Pong) {
+	p.mu.Lock()
+	sentAt, ok := p.outstanding[pong.Nonce]
+	delete(p.outstanding, pong.Nonce)
+	p.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	// time.Since uses the monotonic clock, so wall clock jumps don't matter
+	rtt := time.Since(sentAt)
+	p.ctrl.ObserveRTT(p.workerID, rtt)
+	p.ctrl.ObserveProbe(p.workerID, true)
+	if p.onRTT != nil {
+		p.onRTT(rtt)
+	}
+}
+
+// ping sends the next Ping
+func (p *Prober) ping() error {
+	now := time.Now()
+
+	p.mu.Lock()
+	p.nonce++
+	nonce := p.nonce
+	p.outstanding[nonce] = now
+	p.mu.Unlock()
+
+	return p.send(&pb.MasterMessage{
+		Payload: &pb.MasterMessage_Ping{
+			Ping: &pb.Ping{
+				Nonce:        nonce,
+				SentUnixNano: now.UnixNano(),
+			},
+		},
+	})
+}
+
+// expire drops pings older than timeout and records them as failed probes
+func (p *Prober) expire(timeout time.Duration) {
+	p.mu.Lock()
+	var failed int
+	for nonce, sentAt := range p.outstanding {
+		if time.Since(sentAt) > timeout {
+			delete(p.outstanding, nonce)
+			failed++
+		}
+	}
+	p.mu.Unlock()
+
+	for i := 0; i < failed; i++ {
+		p.ctrl.ObserveProbe(p.workerID, false)
+	}
+}
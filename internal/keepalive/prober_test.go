@@ -0,0 +1,85 @@
+package keepalive
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+func TestProberPong(t *testing.T) {
+	c := NewController(DefaultConfig())
+	var sent []*pb.Ping
+	var samples int
+	p := NewProber("w1", c, func(msg *pb.MasterMessage) error {
+		sent = append(sent, msg.GetPing())
+		return nil
+	}, func(time.Duration) { samples++ })
+
+	if err := p.ping(); err != nil {
+		t.Fatal(err)
+	}
+	p.HandlePong(&pb.Pong{Nonce: sent[0].Nonce})
+	// a duplicate or unknown nonce is not a sample
+	p.HandlePong(&pb.Pong{Nonce: sent[0].Nonce})
+	p.HandlePong(&pb.Pong{Nonce: 99})
+	if samples != 1 {
+		t.Errorf("%d RTT samples, want 1", samples)
+	}
+
+	// the second ping goes unanswered in time; its late pong is ignored
+	if err := p.ping(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	p.expire(time.Millisecond)
+	p.HandlePong(&pb.Pong{Nonce: sent[1].Nonce})
+	if _, _, failRate := c.Stats("w1"); failRate != 0.5 || samples != 1 {
+		t.Errorf("FailRate = %v with %d samples, want 0.5 with 1", failRate, samples)
+	}
+	if sent[0].Nonce == sent[1].Nonce {
+		t.Errorf("both pings carry nonce %d", sent[0].Nonce)
+	}
+}
+
+func TestProberRun(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.BaseTime, cfg.MinTime = 5*time.Millisecond, time.Millisecond
+	c := NewController(cfg)
+
+	pings := make(chan *pb.Ping, 8)
+	var p *Prober
+	p = NewProber("w1", c, func(msg *pb.MasterMessage) error {
+		if len(pings) == 3 {
+			return errors.New("stream closed")
+		}
+		pings <- msg.GetPing()
+		p.HandlePong(&pb.Pong{Nonce: msg.GetPing().Nonce})
+		return nil
+	}, nil)
+
+	// Run stops at the first failed send
+	done := make(chan struct{})
+	go func() {
+		p.Run(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after a failed send")
+	}
+	if len(pings) != 3 {
+		t.Errorf("%d pings sent, want 3", len(pings))
+	}
+	if mean, _, _ := c.Stats("w1"); mean <= 0 {
+		t.Errorf("RTT mean = %v, want samples from the pongs", mean)
+	}
+
+	// and when its context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p.Run(ctx)
+}
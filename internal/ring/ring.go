@@ -0,0 +1,87 @@
+// Package ring provides a fixed-size sample buffer for sliding windows of
+// recent measurements, such as RTTs or heartbeat intervals.
+package ring
+
+import "math"
+
+// Number is the constraint of the statistics helpers
+type Number interface {
+	~int | ~int32 | ~int64 | ~float32 | ~float64
+}
+
+// Ring is a fixed-size ring buffer that overwrites its oldest value once full.
+// It is not thread-safe; its owner guards it.
+type Ring[T any] struct {
+	buf  []T
+	next int // index the next value is written to
+	n    int // number of values stored
+}
+
+// New constructs a Ring holding up to size values
+func New[T any](size int) *Ring[T] {
+	return &Ring[T]{buf: make([]T, size)}
+}
+
+// Add records a value. Once the ring is full the oldest value is
+// overwritten and returned with evicted set.
+func (r *Ring[T]) Add(v T) (old T, evicted bool) {
+	if r.n == len(r.buf) {
+		old, evicted = r.buf[r.next], true
+	} else {
+		r.n++
+	}
+	r.buf[r.next] = v
+	r.next = (r.next + 1) % len(r.buf)
+	return old, evicted
+}
+
+// Len returns the number of values stored
+func (r *Ring[T]) Len() int {
+	return r.n
+}
+
+// Values returns a copy of the values, oldest first
+func (r *Ring[T]) Values() []T {
+	out := make([]T, 0, r.n)
+	if r.n == len(r.buf) {
+		out = append(out, r.buf[r.next:]...)
+	}
+	return append(out, r.buf[:r.next]...)
+}
+
+// Last returns the most recent value; ok is false if the ring is empty
+func (r *Ring[T]) Last() (v T, ok bool) {
+	if r.n == 0 {
+		return v, false
+	}
+	return r.buf[(r.next-1+len(r.buf))%len(r.buf)], true
+}
+
+// Mean returns the average of the values, 0 if empty
+func Mean[T Number](r *Ring[T]) T {
+	if r.n == 0 {
+		return 0
+	}
+	var sum T
+	for _, v := range r.buf[:r.n] {
+		sum += v
+	}
+	return sum / T(r.n)
+}
+
+// Std returns the population standard deviation of the values, 0 if empty
+func Std[T Number](r *Ring[T]) float64 {
+	if r.n == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range r.buf[:r.n] {
+		sum += float64(v)
+	}
+	mean := sum / float64(r.n)
+	var sq float64
+	for _, v := range r.buf[:r.n] {
+		sq += (float64(v) - mean) * (float64(v) - mean)
+	}
+	return math.Sqrt(sq / float64(r.n))
+}
@@ -0,0 +1,63 @@
+package ring
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		add     []int
+		values  []int
+		evicted []int // values overwritten, in order
+	}{
+		{"empty", 3, nil, []int{}, nil},
+		{"partly filled", 3, []int{1, 2}, []int{1, 2}, nil},
+		{"full", 3, []int{1, 2, 3}, []int{1, 2, 3}, nil},
+		{"wrapped", 3, []int{1, 2, 3, 4, 5}, []int{3, 4, 5}, []int{1, 2}},
+		{"wrapped twice", 2, []int{1, 2, 3, 4, 5}, []int{4, 5}, []int{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New[int](tt.size)
+			var evicted []int
+			for _, v := range tt.add {
+				if old, ok := r.Add(v); ok {
+					evicted = append(evicted, old)
+				}
+			}
+			if got := r.Values(); !slices.Equal(got, tt.values) {
+				t.Errorf("Values() = %v, want %v", got, tt.values)
+			}
+			if r.Len() != len(tt.values) {
+				t.Errorf("Len() = %d, want %d", r.Len(), len(tt.values))
+			}
+			if !slices.Equal(evicted, tt.evicted) {
+				t.Errorf("evicted %v, want %v", evicted, tt.evicted)
+			}
+			last, ok := r.Last()
+			if ok != (len(tt.add) > 0) || (ok && last != tt.add[len(tt.add)-1]) {
+				t.Errorf("Last() = %d, %v", last, ok)
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	r := New[float64](4)
+	if Mean(r) != 0 || Std(r) != 0 {
+		t.Errorf("empty ring: mean %v, std %v", Mean(r), Std(r))
+	}
+	for _, v := range []float64{100, 2, 4, 4, 6} { // 100 is overwritten
+		r.Add(v)
+	}
+	if got := Mean(r); got != 4 {
+		t.Errorf("Mean() = %v, want 4", got)
+	}
+	if got := Std(r); math.Abs(got-math.Sqrt2) > 1e-9 {
+		t.Errorf("Std() = %v, want %v", got, math.Sqrt2)
+	}
+}
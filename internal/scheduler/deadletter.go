@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// defaultDeadLetterCapacity bounds the dead-letter store; the oldest letters
+// are dropped beyond it
+const defaultDeadLetterCapacity = 10000
+
+// Failure is one failed attempt of a task
+type Failure struct {
+	Attempt  int32
+	WorkerID string
+	Error    string
+	Class    pb.ErrorClass
+	At       time.Time
+}
+
+// DeadLetter is a task that failed and will not be retried
+type DeadLetter struct {
+	Task       Task               // final state of the task record
+	Assignment *pb.TaskAssignment // as dispatched on the last attempt
+	Failures   []Failure          // every failed attempt, oldest first
+	Reason     string             // why it was not retried
+	DeadAt     time.Time
+}
+
+// DeadLetterStore keeps the tasks that exhausted their retry policy so they
+// can be inspected and replayed.
+// It is a thread-safe component; callers always get copies.
+type DeadLetterStore struct {
+	mu       sync.Mutex
+	letters  map[string]*DeadLetter // key is task_id
+	capacity int
+}
+
+// NewDeadLetterStore constructs a DeadLetterStore holding up to capacity
+// letters (0 = default)
+func NewDeadLetterStore(capacity int) *DeadLetterStore {
+	if capacity <= 0 {
+		capacity = defaultDeadLetterCapacity
+	}
+	return &DeadLetterStore{
+		letters:  make(map[string]*DeadLetter),
+		capacity: capacity,
+	}
+}
+
+// Add stores a dead letter, dropping the oldest one when the store is full
+func (s *DeadLetterStore) Add(letter DeadLetter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.letters[letter.Task.ID]; !ok && len(s.letters) >= s.capacity {
+		var oldest *DeadLetter
+		for _, l := range s.letters {
+			if oldest == nil || l.DeadAt.Before(oldest.DeadAt) {
+				oldest = l
+			}
+		}
+		delete(s.letters, oldest.Task.ID)
+		log.Printf("[DeadLetterStore] Store full; dropped task %s", oldest.Task.ID)
+	}
+	s.letters[letter.Task.ID] = &letter
+}
+
+// Get returns a copy of a task's dead letter
+func (s *DeadLetterStore) Get(taskID string) (DeadLetter, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.letters[taskID]
+	if !ok {
+		return DeadLetter{}, false
+	}
+	return *l, true
+}
+
+// List returns copies of the dead letters whose task matches filter,
+// oldest first
+func (s *DeadLetterStore) List(filter TaskFilter) []DeadLetter {
+	s.mu.Lock()
+	out := make([]DeadLetter, 0)
+	for _, l := range s.letters {
+		if filter.matches(&l.Task) {
+			out = append(out, *l)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].DeadAt.Equal(out[j].DeadAt) {
+			return out[i].Task.ID < out[j].Task.ID
+		}
+		return out[i].DeadAt.Before(out[j].DeadAt)
+	})
+	if filter.Limit > 0 && len(out) > filter.Limit {
+		out = out[:filter.Limit]
+	}
+	return out
+}
+
+// Remove deletes and returns a task's dead letter
+func (s *DeadLetterStore) Remove(taskID string) (DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.letters[taskID]
+	if !ok {
+		return DeadLetter{}, fmt.Errorf("dead letter for task %s: %w", taskID, ErrTaskNotFound)
+	}
+	delete(s.letters, taskID)
+	return *l, nil
+}
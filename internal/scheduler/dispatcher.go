@@ -0,0 +1,913 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultRetryInterval is how long the Dispatcher waits before trying again
+// when no worker can take a task
+const defaultRetryInterval = 500 * time.Millisecond
+
+// defaultUnschedulableTimeout gives a worker large enough for a task time to
+// register before the task fails
+const defaultUnschedulableTimeout = time.Minute
+
+// defaultReclaimGrace covers a worker's first reconnect attempts
+const defaultReclaimGrace = 5 * time.Second
+
+// defaultTimeoutGrace covers the worker's kill grace and a heartbeat
+const defaultTimeoutGrace = 10 * time.Second
+
+// Dispatcher pulls tasks from a pending queue, picks a worker through the
+// StateManager and pushes the task over that worker's stream.
+// It is a thread-safe component.
+type Dispatcher struct {
+	sm    *StateManager
+	tasks *TaskStore // lifecycle of every submitted task
+
+	mu      sync.Mutex
+	pending []*pb.TaskAssignment    // FIFO queue of tasks waiting for a worker
+	notify  chan struct{}           // signalled (non-blocking) when a task is queued
+	owners  map[string]Acknowledger // key is task_id; tasks submitted via SubmitFrom
+	// returned holds tasks handed back to their source after a worker loss,
+	// waiting for redelivery; value is the attempt the redelivery will be
+	returned map[string]int32
+	// orphans holds the tasks of lost workers during ReclaimGrace
+	orphans map[string]*pb.TaskAssignment // key is task_id
+	// failures holds the failed attempts of unfinished tasks
+	failures map[string][]Failure // key is task_id
+	// unschedulable holds when a pending task first found no worker large
+	// enough for its requests
+	unschedulable map[string]time.Time // key is task_id
+	deadLetters   *DeadLetterStore
+	// leases holds the lease of every dispatched attempt
+	leases map[string]*lease // key is task_id
+	tokens atomic.Uint64     // last fencing token handed out
+
+	RetryInterval time.Duration
+	// ReclaimGrace is how long the tasks of a lost worker wait for it to
+	// reconnect before they are requeued; 0 requeues them right away
+	ReclaimGrace time.Duration
+	// TimeoutGrace is how long past its timeout the Master waits for a
+	// task's result before timing the attempt out itself. Timed-out
+	// attempts are retried like any other failure, per the retry policy
+	// (ERROR_CLASS_TIMEOUT).
+	TimeoutGrace time.Duration
+	// DefaultRetryPolicy applies to tasks submitted without a retry policy;
+	// nil = no retries
+	DefaultRetryPolicy *pb.RetryPolicy
+	// UnschedulableTimeout is how long a task may find no worker its
+	// placement allows whose capacity could ever hold its requests before it
+	// fails and is dead-lettered; 0 keeps it waiting forever
+	UnschedulableTimeout time.Duration
+	// AckTimeout is how long a worker has to acknowledge an assignment, and
+	// LeaseDuration how long an acknowledged attempt stays leased without a
+	// heartbeat renewing it; the attempt is revoked and requeued after either
+	AckTimeout    time.Duration
+	LeaseDuration time.Duration
+}
+
+// NewDispatcher constructs a Dispatcher on top of a StateManager
+func NewDispatcher(sm *StateManager) *Dispatcher {
+	d := &Dispatcher{
+		sm:                   sm,
+		tasks:                NewTaskStore(),
+		notify:               make(chan struct{}, 1),
+		owners:               make(map[string]Acknowledger),
+		returned:             make(map[string]int32),
+		orphans:              make(map[string]*pb.TaskAssignment),
+		failures:             make(map[string][]Failure),
+		unschedulable:        make(map[string]time.Time),
+		deadLetters:          NewDeadLetterStore(0),
+		leases:               make(map[string]*lease),
+		RetryInterval:        defaultRetryInterval,
+		ReclaimGrace:         defaultReclaimGrace,
+		TimeoutGrace:         defaultTimeoutGrace,
+		UnschedulableTimeout: defaultUnschedulableTimeout,
+		AckTimeout:           defaultAckTimeout,
+		LeaseDuration:        defaultLeaseDuration,
+	}
+	d.tokens.Store(uint64(time.Now().UnixNano()))
+	return d
+}
+
+// Tasks returns the task store, used to answer "where is task X right now"
+func (d *Dispatcher) Tasks() *TaskStore {
+	return d.tasks
+}
+
+// DeadLetters returns the store of tasks that failed for good
+func (d *Dispatcher) DeadLetters() *DeadLetterStore {
+	return d.deadLetters
+}
+
+// Submit records a new task as Pending and adds it to the back of the queue.
+// Task IDs must be unique; a task repeating a recent idempotency key is
+// rejected with ErrDuplicateTask.
+func (d *Dispatcher) Submit(task *pb.TaskAssignment) error {
+	return d.SubmitFrom(task, nil)
+}
+
+// SubmitFrom is Submit for tasks owned by a source. The source is acked once
+// the task reaches a terminal state, and the task is nacked back to it (with
+// requeue) instead of being requeued locally when its worker is lost; the
+// redelivery then continues the same task record, or is only held for the
+// ack if the task finished or was adopted by its worker in the meantime.
+func (d *Dispatcher) SubmitFrom(task *pb.TaskAssignment, owner Acknowledger) error {
+	if err := ValidatePlacement(task.Placement); err != nil {
+		return fmt.Errorf("task %s: %w", task.TaskId, err)
+	}
+	if err := ValidateResources(task.Requests); err != nil {
+		return fmt.Errorf("task %s: %w", task.TaskId, err)
+	}
+	if task.TimeoutMillis < 0 {
+		return fmt.Errorf("task %s: %w: %d ms", task.TaskId, ErrInvalidTimeout, task.TimeoutMillis)
+	}
+	if err := ValidateRetryPolicy(task.RetryPolicy); err != nil {
+		return fmt.Errorf("task %s: %w", task.TaskId, err)
+	}
+	if task.Attempt == 0 {
+		task.Attempt = 1
+	}
+
+	d.mu.Lock()
+	attempt, redelivery := d.returned[task.TaskId]
+	delete(d.returned, task.TaskId)
+	d.mu.Unlock()
+
+	if redelivery {
+		task.Attempt = attempt
+		if t, ok := d.tasks.GetTask(task.TaskId); owner != nil && (!ok || t.State != TaskPending) {
+			// a late result finished the task, or its worker came back and
+			// adopted it, while the source redelivered it: the new delivery
+			// only takes over the old one's settlement
+			d.reattach(task.TaskId, owner)
+			return nil
+		}
+	} else if _, err := d.tasks.Create(task.TaskId, task.TaskName, task.TaskPayload, task.Labels, task.IdempotencyKey); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.pending = append(d.pending, task)
+	if owner != nil {
+		d.owners[task.TaskId] = owner
+	}
+	d.mu.Unlock()
+
+	d.wake()
+	log.Printf("[Dispatcher] Task %s queued", task.TaskId)
+	return nil
+}
+
+// PendingCount returns the number of tasks waiting for a worker
+func (d *Dispatcher) PendingCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.pending)
+}
+
+// Cancel cancels an unfinished task and returns its new state. A task
+// already on a worker is marked Cancelled right away and the worker is told
+// to stop it; it keeps its slot until the worker reports back.
+func (d *Dispatcher) Cancel(taskID, reason string) (Task, error) {
+	if reason == "" {
+		reason = "cancelled by client"
+	}
+
+	// the dispatch loop re-checks the state before sending, so a task it
+	// already popped is dropped as well
+	before, err := d.tasks.Cancel(taskID, reason, time.Now())
+	if err != nil {
+		t, _ := d.tasks.GetTask(taskID)
+		return t, err
+	}
+	d.removePending(taskID)
+	d.mu.Lock()
+	delete(d.orphans, taskID)
+	d.mu.Unlock()
+	d.settle(taskID)
+
+	if before.State == TaskAssigned || before.State == TaskRunning {
+		// a heartbeat still listing the attempt then gets it cancelled again
+		d.dropLease(taskID, before.FencingToken)
+		d.cancelOnWorker(before.WorkerID, taskID, before.FencingToken, reason)
+	}
+	log.Printf("[Dispatcher] Task %s cancelled (was %s): %s", taskID, before.State, reason)
+
+	t, _ := d.tasks.GetTask(taskID)
+	return t, nil
+}
+
+// CancelMatching cancels every unfinished task matching filter and returns
+// the cancelled tasks. Tasks that finish in the meantime are skipped.
+func (d *Dispatcher) CancelMatching(filter TaskFilter, reason string) []Task {
+	filter.States = []TaskState{TaskPending, TaskAssigned, TaskRunning}
+	var cancelled []Task
+	for _, t := range d.tasks.ListTasks(filter) {
+		t, err := d.Cancel(t.ID, reason)
+		if err != nil {
+			continue
+		}
+		cancelled = append(cancelled, t)
+	}
+	return cancelled
+}
+
+// cancelOnWorker tells a worker to stop an attempt of a task. If the worker
+// cannot be reached the task has already left that attempt, so a later
+// requeue skips it.
+func (d *Dispatcher) cancelOnWorker(workerID, taskID string, token uint64, reason string) {
+	msg := &pb.MasterMessage{
+		Payload: &pb.MasterMessage_CancelTask{
+			CancelTask: &pb.CancelTask{
+				TaskId:       taskID,
+				Reason:       reason,
+				FencingToken: token,
+			},
+		},
+	}
+	if err := d.sm.SendToWorker(workerID, msg); err != nil {
+		log.Printf("[Dispatcher] Failed to send cancellation of task %s to worker %s: %v", taskID, workerID, err)
+	}
+}
+
+// Requeue puts the in-flight tasks of a lost worker back at the head of the
+// pending queue with an incremented attempt counter. It is installed as the
+// StateManager's worker-lost handler.
+//
+// The tasks are held for ReclaimGrace first: a worker that only lost its
+// connection reconnects, reports them through Adopt and keeps running them.
+func (d *Dispatcher) Requeue(workerID string, tasks []*pb.TaskAssignment) {
+	d.requeue(workerID, tasks, d.ReclaimGrace, fmt.Sprintf("worker %s lost", workerID))
+}
+
+// RequeueNow is Requeue without the grace period, for workers that
+// deregistered and will not come back
+func (d *Dispatcher) RequeueNow(workerID string, tasks []*pb.TaskAssignment) {
+	d.requeue(workerID, tasks, 0, fmt.Sprintf("worker %s deregistered", workerID))
+}
+
+func (d *Dispatcher) requeue(workerID string, tasks []*pb.TaskAssignment, grace time.Duration, reason string) {
+	if len(tasks) == 0 {
+		return
+	}
+	for _, task := range tasks {
+		task.Attempt++
+		if err := d.tasks.MarkPending(task.TaskId, reason); err != nil {
+			log.Printf("[Dispatcher] Task %s: %v", task.TaskId, err)
+		}
+	}
+	if grace <= 0 {
+		d.release(workerID, tasks)
+		return
+	}
+
+	d.mu.Lock()
+	for _, task := range tasks {
+		d.orphans[task.TaskId] = task
+	}
+	d.mu.Unlock()
+	log.Printf("[Dispatcher] Holding %d tasks of lost worker %s for %v in case it reconnects", len(tasks), workerID, grace)
+
+	time.AfterFunc(grace, func() {
+		// only the tasks nobody adopted in the meantime
+		d.mu.Lock()
+		unclaimed := tasks[:0:0]
+		for _, task := range tasks {
+			if d.orphans[task.TaskId] == task {
+				delete(d.orphans, task.TaskId)
+				unclaimed = append(unclaimed, task)
+			}
+		}
+		d.mu.Unlock()
+		d.release(workerID, unclaimed)
+	})
+}
+
+// release hands the tasks of a lost worker back to their source or queues
+// them again
+func (d *Dispatcher) release(workerID string, tasks []*pb.TaskAssignment) {
+	local := tasks[:0:0]
+	for _, task := range tasks {
+		// cancelled, or a late result arrived meanwhile
+		if t, ok := d.tasks.GetTask(task.TaskId); !ok || t.State != TaskPending {
+			continue
+		}
+
+		// tasks owned by a source go back to it; the source redelivers them
+		if owner := d.takeOwner(task.TaskId); owner != nil {
+			d.mu.Lock()
+			d.returned[task.TaskId] = task.Attempt
+			d.mu.Unlock()
+			err := owner.Nack(task.TaskId, true)
+			if err == nil {
+				log.Printf("[Dispatcher] Returned task %s from lost worker %s to its source", task.TaskId, workerID)
+				continue
+			}
+			// e.g. the broker channel closed: no redelivery will come, so
+			// the task is requeued here instead
+			log.Printf("[Dispatcher] Failed to return task %s to its source: %v", task.TaskId, err)
+			d.mu.Lock()
+			delete(d.returned, task.TaskId)
+			d.mu.Unlock()
+		}
+
+		local = append(local, task)
+		log.Printf("[Dispatcher] Requeueing task %s from lost worker %s (attempt %d)", task.TaskId, workerID, task.Attempt)
+	}
+	if len(local) == 0 {
+		return
+	}
+
+	d.mu.Lock()
+	d.pending = append(local, d.pending...)
+	d.mu.Unlock()
+
+	d.wake()
+}
+
+// Adopt takes over the tasks a (re)registering worker reports it still holds,
+// so they are not run twice:
+//
+//   - tasks the Master does not know (it restarted) are recreated
+//   - tasks waiting to be requeued after the worker was lost go back to it
+//   - tasks already dispatched elsewhere or finished are left alone; the
+//     first result to arrive wins
+func (d *Dispatcher) Adopt(workerID string, inFlight []*pb.InFlightTask) {
+	for _, f := range inFlight {
+		task := f.Task
+		if task == nil {
+			continue
+		}
+
+		t, ok := d.tasks.GetTask(task.TaskId)
+		switch {
+		case !ok:
+			if _, err := d.tasks.Create(task.TaskId, task.TaskName, task.TaskPayload, task.Labels, task.IdempotencyKey); err != nil {
+				log.Printf("[Dispatcher] Cannot adopt task %s: %v", task.TaskId, err)
+				continue
+			}
+		case stale(t, task.FencingToken):
+			// the Master revoked this attempt and dispatched a newer one
+			log.Printf("[Dispatcher] Worker %s holds superseded attempt of task %s (token %d, current %d); stopping it", workerID, task.TaskId, task.FencingToken, t.FencingToken)
+			d.cancelOnWorker(workerID, task.TaskId, task.FencingToken, "superseded by a newer attempt")
+			continue
+		case t.State == TaskPending:
+			d.mu.Lock()
+			delete(d.orphans, task.TaskId)
+			d.mu.Unlock()
+			d.removePending(task.TaskId)
+		case (t.State == TaskAssigned || t.State == TaskRunning) && t.WorkerID == workerID:
+			// the same worker replaced its session before the old one was
+			// noticed as lost; the StateManager kept its in-flight tasks
+			d.renew(workerID, task.TaskId, task.FencingToken)
+			continue
+		case t.State == TaskCancelled:
+			// cancelled while the worker was away; the CancelTask was lost
+			d.cancelOnWorker(workerID, task.TaskId, task.FencingToken, t.LastError)
+			continue
+		default:
+			log.Printf("[Dispatcher] Worker %s still holds task %s, which is %s on worker %q; not adopting it", workerID, task.TaskId, t.State, t.WorkerID)
+			continue
+		}
+
+		if !d.sm.AddInFlight(workerID, task) {
+			log.Printf("[Dispatcher] Cannot adopt task %s: worker %s went away", task.TaskId, workerID)
+			continue
+		}
+		if err := d.tasks.MarkAssigned(task.TaskId, workerID, task.Attempt, task.FencingToken); err != nil {
+			log.Printf("[Dispatcher] Task %s: %v", task.TaskId, err)
+		}
+		d.grantLease(workerID, task, true)
+		if f.Started {
+			if err := d.tasks.MarkRunning(task.TaskId, time.Unix(0, f.StartTimeUnixNano)); err != nil {
+				log.Printf("[Dispatcher] Task %s: %v", task.TaskId, err)
+			}
+		}
+		d.watchTimeout(workerID, task)
+		log.Printf("[Dispatcher] Worker %s adopted task %s (attempt %d)", workerID, task.TaskId, task.Attempt)
+	}
+}
+
+// stale reports whether a message carrying token belongs to an attempt older
+// than the task's current one. Token 0 is not checked.
+func stale(t Task, token uint64) bool {
+	return token != 0 && token != t.FencingToken
+}
+
+// RecordStarted marks a task Running once its worker reports it started
+func (d *Dispatcher) RecordStarted(workerID string, started *pb.TaskStarted) {
+	if t, ok := d.tasks.GetTask(started.TaskId); ok && stale(t, started.FencingToken) {
+		log.Printf("[Dispatcher] Ignoring start of superseded attempt of task %s on worker %s", started.TaskId, workerID)
+		return
+	}
+	// a start also proves the assignment arrived
+	d.renew(workerID, started.TaskId, started.FencingToken)
+	startedAt := time.Unix(0, started.StartTimeUnixNano)
+	if err := d.tasks.MarkRunning(started.TaskId, startedAt); err != nil {
+		log.Printf("[Dispatcher] Ignoring start of task %s on worker %s: %v", started.TaskId, workerID, err)
+	}
+}
+
+// RecordResult stores the outcome a worker reported for a task
+func (d *Dispatcher) RecordResult(workerID string, result *pb.TaskResult) {
+	// fencing: a zombie attempt must not overwrite a newer one, nor free
+	// the slot the newer one holds if it landed on the same worker
+	if t, ok := d.tasks.GetTask(result.TaskId); ok && stale(t, result.FencingToken) {
+		log.Printf("[Dispatcher] Rejected result of task %s from worker %s: token %d was superseded by %d", result.TaskId, workerID, result.FencingToken, t.FencingToken)
+		return
+	}
+	d.dropLease(result.TaskId, result.FencingToken)
+
+	task, held := d.sm.RemoveInFlight(workerID, result.TaskId)
+	if held {
+		// the slot is free again on the worker
+		d.sm.DecrementActiveTasks(workerID)
+	}
+	if result.Status == pb.TaskStatus_TASK_STATUS_CANCELLED {
+		if t, _ := d.tasks.GetTask(result.TaskId); t.State == TaskCancelled || !held {
+			// cancelled, timed out or revoked by the Master, which settled
+			// the attempt when it sent the CancelTask
+			log.Printf("[Dispatcher] Task %s stopped on worker %s", result.TaskId, workerID)
+			return
+		}
+		// the worker stopped an attempt the Master still counted on
+		log.Printf("[Dispatcher] Task %s was stopped on worker %s without being cancelled: %s", result.TaskId, workerID, result.Error)
+		d.failed(workerID, task, result)
+		return
+	}
+	if !held {
+		if task = d.unqueue(result.TaskId); task != nil {
+			// the worker was considered lost and the task requeued, but it
+			// finished after all; don't run it again
+			log.Printf("[Dispatcher] Late result for requeued task %s from worker %s; dropped the retry", result.TaskId, workerID)
+		}
+	}
+	if held && result.Status != pb.TaskStatus_TASK_STATUS_SUCCEEDED {
+		d.failed(workerID, task, result)
+		return
+	}
+
+	state := resultState(result.Status)
+	d.sm.RecordOutcome(workerID, state == TaskSucceeded)
+	finishedAt := time.Unix(0, result.EndTimeUnixNano)
+	if err := d.tasks.Finish(result.TaskId, state, result.Output, result.Error, finishedAt); err != nil {
+		// e.g. a second result after the task was requeued and finished elsewhere
+		log.Printf("[Dispatcher] Ignoring result of task %s from worker %s: %v", result.TaskId, workerID, err)
+		return
+	}
+	if state != TaskSucceeded {
+		// first result wins, so a late failure is not retried either
+		d.recordFailure(workerID, result)
+		d.bury(result.TaskId, task, "late result of an attempt whose worker was considered lost")
+	}
+
+	d.settle(result.TaskId)
+
+	elapsed := time.Duration(result.EndTimeUnixNano - result.StartTimeUnixNano)
+	if result.Status == pb.TaskStatus_TASK_STATUS_SUCCEEDED {
+		log.Printf("[Dispatcher] Task %s succeeded on worker %s in %v", result.TaskId, workerID, elapsed)
+	} else {
+		log.Printf("[Dispatcher] Task %s %s on worker %s in %v: %s", result.TaskId, result.Status, workerID, elapsed, result.Error)
+	}
+}
+
+// Run is the dispatch loop. It blocks until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	log.Printf("[Dispatcher] Dispatch loop started")
+	go d.reapLeases(ctx)
+	d.tasks.StartPruner(ctx)
+	for {
+		// 1. wait for pending tasks and take the whole queue
+		tasks, ok := d.next(ctx)
+		if !ok {
+			log.Printf("[Dispatcher] Dispatch loop stopped")
+			return
+		}
+
+		// 2. try every task; one that cannot be placed (e.g. its worker pool
+		// is full) must not hold back tasks that fit elsewhere
+		var blocked []*pb.TaskAssignment
+		var firstErr error
+		for _, task := range tasks {
+			// skip tasks that left the Pending state while queued (e.g. a
+			// late result arrived for a requeued task)
+			if t, ok := d.tasks.GetTask(task.TaskId); !ok || t.State != TaskPending {
+				continue
+			}
+			err := d.dispatch(task)
+			if d.unschedulableFor(task, err) {
+				continue
+			}
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("task %s: %w", task.TaskId, err)
+				}
+				blocked = append(blocked, task)
+			}
+		}
+		if len(blocked) == 0 {
+			continue
+		}
+
+		// 3. put the rest back at the head of the queue so ordering is
+		// preserved, then back off
+		log.Printf("[Dispatcher] Could not dispatch %d tasks, retrying in %v (%v)", len(blocked), d.RetryInterval, firstErr)
+		d.pushFront(blocked)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.RetryInterval):
+		}
+	}
+}
+
+// dispatch selects a worker and sends the task to it
+func (d *Dispatcher) dispatch(task *pb.TaskAssignment) error {
+	workerID, _, err := d.sm.SelectWorker(TaskSpec{
+		ID:        task.TaskId,
+		Name:      task.TaskName,
+		Placement: task.Placement,
+		Requests:  ResourcesFromProto(task.Requests),
+	})
+	if err != nil {
+		return err
+	}
+
+	// track before sending so a fast result can never arrive for an unknown task
+	task.FencingToken = d.nextToken()
+	if !d.sm.AddInFlight(workerID, task) {
+		return fmt.Errorf("worker %s went away", workerID)
+	}
+	if err := d.tasks.MarkAssigned(task.TaskId, workerID, task.Attempt, task.FencingToken); err != nil {
+		d.sm.RemoveInFlight(workerID, task.TaskId)
+		return err
+	}
+	d.grantLease(workerID, task, false)
+
+	msg := &pb.MasterMessage{
+		Payload: &pb.MasterMessage_TaskAssignment{
+			TaskAssignment: task,
+		},
+	}
+	if err := d.sm.SendToWorker(workerID, msg); err != nil {
+		d.sm.RemoveInFlight(workerID, task.TaskId)
+		d.dropLease(task.TaskId, task.FencingToken)
+		d.tasks.MarkPending(task.TaskId, fmt.Sprintf("send to worker %s failed: %v", workerID, err))
+		return err
+	}
+
+	// [Important] bump the load right away; the worker's next heartbeat
+	// will correct it
+	d.sm.IncrementActiveTasks(workerID)
+	d.watchTimeout(workerID, task)
+	log.Printf("[Dispatcher] Task %s dispatched to worker %s", task.TaskId, workerID)
+	return nil
+}
+
+// unschedulableFor tracks how long a task has found no worker matching its
+// placement and large enough for its requests. Once that exceeds UnschedulableTimeout the task fails,
+// is dead-lettered and true is returned. Any other dispatch outcome resets
+// the clock.
+func (d *Dispatcher) unschedulableFor(task *pb.TaskAssignment, err error) bool {
+	now := time.Now()
+	d.mu.Lock()
+	if !errors.Is(err, ErrUnschedulable) || d.UnschedulableTimeout <= 0 {
+		delete(d.unschedulable, task.TaskId)
+		d.mu.Unlock()
+		return false
+	}
+	since, ok := d.unschedulable[task.TaskId]
+	if !ok {
+		d.unschedulable[task.TaskId] = now
+		since = now
+	}
+	d.mu.Unlock()
+	if now.Sub(since) < d.UnschedulableTimeout {
+		return false
+	}
+
+	if err := d.tasks.Finish(task.TaskId, TaskFailed, nil, err.Error(), now); err != nil {
+		log.Printf("[Dispatcher] Task %s: %v", task.TaskId, err)
+		return true
+	}
+	log.Printf("[Dispatcher] Task %s failed: %v", task.TaskId, err)
+	d.bury(task.TaskId, task, fmt.Sprintf("no worker could hold the task for %v", d.UnschedulableTimeout))
+	d.settle(task.TaskId)
+	return true
+}
+
+// watchTimeout times an attempt out if its result has not arrived within
+// the task's timeout plus TimeoutGrace, for when the worker cannot enforce
+// the timeout itself (hung host, result lost). The worker counts from the
+// start, so a Running task is measured from StartedAt and one that never
+// started from the dispatch.
+func (d *Dispatcher) watchTimeout(workerID string, task *pb.TaskAssignment) {
+	if task.TimeoutMillis <= 0 {
+		return
+	}
+	limit := time.Duration(task.TimeoutMillis)*time.Millisecond + d.TimeoutGrace
+	// task is shared with later attempts; the token pins this one
+	token := task.FencingToken
+
+	var check func()
+	check = func() {
+		t, ok := d.tasks.GetTask(task.TaskId)
+		if !ok || t.WorkerID != workerID || t.FencingToken != token ||
+			(t.State != TaskAssigned && t.State != TaskRunning) {
+			// finished, requeued or cancelled in the meantime
+			return
+		}
+		if t.State == TaskRunning {
+			if wait := time.Until(t.StartedAt.Add(limit)); wait > 0 {
+				time.AfterFunc(wait, check)
+				return
+			}
+		}
+
+		if _, held := d.sm.RemoveInFlight(workerID, task.TaskId); !held {
+			// a result (or a revoke) took the attempt in the meantime
+			return
+		}
+		d.sm.DecrementActiveTasks(workerID)
+		d.dropLease(task.TaskId, token)
+		d.cancelOnWorker(workerID, task.TaskId, token, "timed out")
+		now := time.Now().UnixNano()
+		d.failed(workerID, task, &pb.TaskResult{
+			TaskId:            task.TaskId,
+			Status:            pb.TaskStatus_TASK_STATUS_TIMED_OUT,
+			Error:             fmt.Sprintf("no result from worker %s within %v", workerID, limit),
+			ErrorClass:        pb.ErrorClass_ERROR_CLASS_TIMEOUT,
+			StartTimeUnixNano: now,
+			EndTimeUnixNano:   now,
+			FencingToken:      token,
+		})
+	}
+	time.AfterFunc(limit, check)
+}
+
+// resultState maps a reported TaskStatus to the task's terminal state.
+// Cancellations by the Master settle the task when they are sent, so a
+// CANCELLED result getting here was not asked for: the attempt failed.
+func resultState(status pb.TaskStatus) TaskState {
+	switch status {
+	case pb.TaskStatus_TASK_STATUS_SUCCEEDED:
+		return TaskSucceeded
+	case pb.TaskStatus_TASK_STATUS_TIMED_OUT:
+		return TaskTimedOut
+	default:
+		return TaskFailed
+	}
+}
+
+// failed handles an attempt that failed or timed out, reported by the worker
+// or noticed by watchTimeout. The task is retried after a backoff if its
+// retry policy allows; otherwise it ends Failed/TimedOut and moves to the
+// dead-letter store. The worker's slot must already be freed.
+func (d *Dispatcher) failed(workerID string, task *pb.TaskAssignment, result *pb.TaskResult) {
+	d.sm.RecordOutcome(workerID, false)
+	// attempts lost with their worker were requeued without counting
+	// against the retry budget, so failures are counted separately
+	failures := d.recordFailure(workerID, result)
+
+	policy := task.RetryPolicy
+	if policy == nil {
+		policy = d.DefaultRetryPolicy
+	}
+	retry, reason := retryVerdict(policy, failures, result.ErrorClass)
+	if retry {
+		delay := retryDelay(policy, failures)
+		task.Attempt++
+		if err := d.tasks.ScheduleRetry(task.TaskId, result.Error, time.Now().Add(delay)); err != nil {
+			log.Printf("[Dispatcher] Task %s: %v", task.TaskId, err)
+			return
+		}
+		log.Printf("[Dispatcher] Task %s %s on worker %s (%s); retrying in %v (attempt %d)",
+			task.TaskId, result.Status, workerID, result.Error, delay.Round(time.Millisecond), task.Attempt)
+		d.requeueAfter(task, delay)
+		return
+	}
+
+	state := resultState(result.Status)
+	finishedAt := time.Unix(0, result.EndTimeUnixNano)
+	if err := d.tasks.Finish(task.TaskId, state, result.Output, result.Error, finishedAt); err != nil {
+		log.Printf("[Dispatcher] Ignoring result of task %s from worker %s: %v", task.TaskId, workerID, err)
+		return
+	}
+	log.Printf("[Dispatcher] Task %s %s on worker %s: %s", task.TaskId, result.Status, workerID, result.Error)
+	d.bury(task.TaskId, task, reason)
+	d.settle(task.TaskId)
+}
+
+// recordFailure remembers a failed attempt for the task's dead letter and
+// returns the number of failed attempts so far
+func (d *Dispatcher) recordFailure(workerID string, result *pb.TaskResult) int32 {
+	t, _ := d.tasks.GetTask(result.TaskId)
+	f := Failure{
+		Attempt:  t.Attempts,
+		WorkerID: workerID,
+		Error:    result.Error,
+		Class:    result.ErrorClass,
+		At:       time.Unix(0, result.EndTimeUnixNano),
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.failures[result.TaskId] = append(d.failures[result.TaskId], f)
+	return int32(len(d.failures[result.TaskId]))
+}
+
+// bury moves a task that failed for good into the dead-letter store. task
+// is the last assignment, nil if it is not known.
+func (d *Dispatcher) bury(taskID string, task *pb.TaskAssignment, reason string) {
+	t, _ := d.tasks.GetTask(taskID)
+	d.mu.Lock()
+	failures := d.failures[taskID]
+	delete(d.failures, taskID)
+	d.mu.Unlock()
+
+	d.deadLetters.Add(DeadLetter{
+		Task:       t,
+		Assignment: task,
+		Failures:   failures,
+		Reason:     reason,
+		DeadAt:     time.Now(),
+	})
+	log.Printf("[Dispatcher] Task %s moved to the dead-letter store: %s", taskID, reason)
+}
+
+// requeueAfter puts a retry back at the head of the queue once its backoff
+// has passed, unless the task left the Pending state meanwhile
+func (d *Dispatcher) requeueAfter(task *pb.TaskAssignment, delay time.Duration) {
+	push := func() {
+		if t, ok := d.tasks.GetTask(task.TaskId); !ok || t.State != TaskPending {
+			return
+		}
+		d.pushFront([]*pb.TaskAssignment{task})
+		d.wake()
+	}
+	if delay <= 0 {
+		push()
+		return
+	}
+	time.AfterFunc(delay, push)
+}
+
+// Replay submits a dead-lettered task again as a new task, under newID or a
+// generated ID, and removes it from the dead-letter store. The new task
+// starts over at attempt 1 with the same retry policy.
+func (d *Dispatcher) Replay(taskID, newID string) (string, error) {
+	letter, err := d.deadLetters.Remove(taskID)
+	if err != nil {
+		return "", err
+	}
+
+	task := &pb.TaskAssignment{
+		TaskName:    letter.Task.Name,
+		TaskPayload: letter.Task.Payload,
+		Labels:      letter.Task.Labels,
+	}
+	if letter.Assignment != nil {
+		task = proto.Clone(letter.Assignment).(*pb.TaskAssignment)
+	}
+	if newID == "" {
+		newID = NewTaskID()
+	}
+	task.TaskId = newID
+	task.Attempt = 0
+	// a replay is deliberately a second task
+	task.IdempotencyKey = ""
+
+	if err := d.Submit(task); err != nil {
+		// keep the letter so the replay can be tried again
+		d.deadLetters.Add(letter)
+		return "", err
+	}
+	log.Printf("[Dispatcher] Replayed dead-lettered task %s as %s", taskID, newID)
+	return newID, nil
+}
+
+// next blocks until tasks are pending and removes them all from the queue
+func (d *Dispatcher) next(ctx context.Context) ([]*pb.TaskAssignment, bool) {
+	for {
+		d.mu.Lock()
+		if len(d.pending) > 0 {
+			tasks := d.pending
+			d.pending = nil
+			d.mu.Unlock()
+			return tasks, true
+		}
+		d.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-d.notify:
+		}
+	}
+}
+
+// pushFront puts tasks back at the head of the queue, keeping their order
+func (d *Dispatcher) pushFront(tasks []*pb.TaskAssignment) {
+	d.mu.Lock()
+	d.pending = append(tasks[:len(tasks):len(tasks)], d.pending...)
+	d.mu.Unlock()
+}
+
+// takeOwner removes and returns the source owning a task, nil if none
+func (d *Dispatcher) takeOwner(taskID string) Acknowledger {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	owner := d.owners[taskID]
+	delete(d.owners, taskID)
+	return owner
+}
+
+// reattach makes owner the source of a known task again, e.g. for a delivery
+// that replaces one the source lost. A task that already finished is acked
+// to it right away. It reports false, leaving the delivery to the caller, if
+// another source owns the task.
+func (d *Dispatcher) reattach(taskID string, owner Acknowledger) bool {
+	d.mu.Lock()
+	if cur, ok := d.owners[taskID]; ok && cur != owner {
+		d.mu.Unlock()
+		return false
+	}
+	d.owners[taskID] = owner
+	d.mu.Unlock()
+
+	// checked after attaching, so a task finishing concurrently is acked
+	// either by its own settle or here
+	if t, ok := d.tasks.GetTask(taskID); !ok || t.State.Terminal() {
+		d.settle(taskID)
+	}
+	return true
+}
+
+// settle acks a task that reached a terminal state to its source, if any
+func (d *Dispatcher) settle(taskID string) {
+	d.mu.Lock()
+	delete(d.failures, taskID)
+	delete(d.unschedulable, taskID)
+	d.mu.Unlock()
+
+	if owner := d.takeOwner(taskID); owner != nil {
+		if err := owner.Ack(taskID); err != nil {
+			log.Printf("[Dispatcher] Failed to ack task %s to its source: %v", taskID, err)
+		}
+	}
+}
+
+// unqueue takes a task out of the pending queue or the orphans held for a
+// lost worker and returns its assignment, nil if it was in neither
+func (d *Dispatcher) unqueue(taskID string) *pb.TaskAssignment {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if task, ok := d.orphans[taskID]; ok {
+		delete(d.orphans, taskID)
+		return task
+	}
+	for i, t := range d.pending {
+		if t.TaskId == taskID {
+			d.pending = append(d.pending[:i], d.pending[i+1:]...)
+			return t
+		}
+	}
+	return nil
+}
+
+// removePending drops a task from the pending queue; it reports whether the
+// task was queued
+func (d *Dispatcher) removePending(taskID string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, t := range d.pending {
+		if t.TaskId == taskID {
+			d.pending = append(d.pending[:i], d.pending[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// wake signals the dispatch loop without blocking
+func (d *Dispatcher) wake() {
+	select {
+	case d.notify <- struct{}{}:
+	default:
+	}
+}
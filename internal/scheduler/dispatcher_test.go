@@ -0,0 +1,756 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeStream is a worker's Connect stream that records what the Master sends
+type fakeStream struct {
+	pb.SchedulerService_ConnectServer
+	sent chan *pb.MasterMessage
+}
+
+func newFakeStream() *fakeStream {
+	return &fakeStream{sent: make(chan *pb.MasterMessage, 64)}
+}
+
+// Send copies the message as gRPC would serialize it; the Dispatcher keeps
+// updating the assignment for later attempts
+func (s *fakeStream) Send(msg *pb.MasterMessage) error {
+	s.sent <- proto.Clone(msg).(*pb.MasterMessage)
+	return nil
+}
+
+// assignment waits for the next TaskAssignment, skipping other messages
+func (s *fakeStream) assignment(t *testing.T) *pb.TaskAssignment {
+	t.Helper()
+	for {
+		select {
+		case msg := <-s.sent:
+			if a := msg.GetTaskAssignment(); a != nil {
+				return a
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("no task assignment sent")
+		}
+	}
+}
+
+// cancellation waits for the next CancelTask, skipping other messages
+func (s *fakeStream) cancellation(t *testing.T) *pb.CancelTask {
+	t.Helper()
+	for {
+		select {
+		case msg := <-s.sent:
+			if c := msg.GetCancelTask(); c != nil {
+				return c
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("no cancellation sent")
+		}
+	}
+}
+
+// fakeOwner is a task source recording how its tasks were settled
+type fakeOwner struct {
+	mu      sync.Mutex
+	acked   []string
+	nacked  []string
+	nackErr error
+}
+
+func (o *fakeOwner) Ack(taskID string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.acked = append(o.acked, taskID)
+	return nil
+}
+
+func (o *fakeOwner) Nack(taskID string, requeue bool) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.nacked = append(o.nacked, taskID)
+	return o.nackErr
+}
+
+func (o *fakeOwner) counts() (acked, nacked int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.acked), len(o.nacked)
+}
+
+// newTestDispatcher starts a Dispatcher with one registered worker, w1
+func newTestDispatcher(t *testing.T, configure func(d *Dispatcher)) (*Dispatcher, *StateManager, *fakeStream) {
+	t.Helper()
+	sm := NewStateManager()
+	d := NewDispatcher(sm)
+	d.RetryInterval = 10 * time.Millisecond
+	if configure != nil {
+		configure(d)
+	}
+	stream := newFakeStream()
+	sm.RegisterWorker(&pb.RegisterRequest{Hostname: "host-1", MaxConcurrency: 4}, "w1", stream, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go d.Run(ctx)
+	return d, sm, stream
+}
+
+func TestDispatcherOrder(t *testing.T) {
+	sm := NewStateManager()
+	d := NewDispatcher(sm)
+	for _, id := range []string{"t1", "t2", "t3"} {
+		d.Submit(&pb.TaskAssignment{TaskId: id, TaskName: "job"})
+	}
+	stream := newFakeStream()
+	sm.RegisterWorker(&pb.RegisterRequest{Hostname: "host-1", MaxConcurrency: 4}, "w1", stream, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+	for _, want := range []string{"t1", "t2", "t3"} {
+		if a := stream.assignment(t); a.TaskId != want {
+			t.Errorf("assigned %s, want %s", a.TaskId, want)
+		}
+	}
+	// each dispatch counts against the worker until its next heartbeat
+	if active, _ := sm.GetGlobalLoad(); active != 3 {
+		t.Errorf("%d active tasks after three dispatches, want 3", active)
+	}
+}
+
+func TestDispatcherNoWorker(t *testing.T) {
+	d, sm, w1 := newTestDispatcher(t, nil)
+	sm.UnregisterWorker("w1", w1)
+	d.Submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "job"})
+
+	// the task waits in the queue until a worker can take it
+	time.Sleep(50 * time.Millisecond)
+	if n := d.PendingCount(); n != 1 {
+		t.Fatalf("%d tasks pending without a worker, want 1", n)
+	}
+	stream := newFakeStream()
+	sm.RegisterWorker(&pb.RegisterRequest{Hostname: "host-2", MaxConcurrency: 4}, "w2", stream, nil)
+	if a := stream.assignment(t); a.TaskId != "t1" {
+		t.Errorf("assigned %s, want t1", a.TaskId)
+	}
+}
+
+// waitState polls until a task reaches state
+func waitState(t *testing.T, d *Dispatcher, taskID string, state TaskState) Task {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		task, _ := d.Tasks().GetTask(taskID)
+		if task.State == state {
+			return task
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("task %s is %s, want %s", taskID, task.State, state)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// result builds the TaskResult of an attempt
+func result(a *pb.TaskAssignment, status pb.TaskStatus, class pb.ErrorClass) *pb.TaskResult {
+	now := time.Now().UnixNano()
+	r := &pb.TaskResult{
+		TaskId:            a.TaskId,
+		FencingToken:      a.FencingToken,
+		Status:            status,
+		StartTimeUnixNano: now,
+		EndTimeUnixNano:   now,
+	}
+	if status != pb.TaskStatus_TASK_STATUS_SUCCEEDED {
+		r.Error = "boom"
+		r.ErrorClass = class
+	}
+	return r
+}
+
+func TestDispatcherResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		status pb.TaskStatus
+		want   TaskState
+	}{
+		{"succeeded", pb.TaskStatus_TASK_STATUS_SUCCEEDED, TaskSucceeded},
+		{"failed", pb.TaskStatus_TASK_STATUS_FAILED, TaskFailed},
+		{"timed out", pb.TaskStatus_TASK_STATUS_TIMED_OUT, TaskTimedOut},
+		// stopped although nobody cancelled it: a failed attempt
+		{"cancelled by the worker", pb.TaskStatus_TASK_STATUS_CANCELLED, TaskFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, sm, stream := newTestDispatcher(t, nil)
+			owner := &fakeOwner{}
+			if err := d.SubmitFrom(&pb.TaskAssignment{TaskId: "t1", TaskName: "job"}, owner); err != nil {
+				t.Fatal(err)
+			}
+			a := stream.assignment(t)
+			if a.FencingToken == 0 || a.Attempt != 1 {
+				t.Fatalf("assignment token %d, attempt %d", a.FencingToken, a.Attempt)
+			}
+			d.RecordAccepted("w1", &pb.TaskAccepted{TaskId: "t1", FencingToken: a.FencingToken})
+			d.RecordStarted("w1", &pb.TaskStarted{TaskId: "t1", StartTimeUnixNano: time.Now().UnixNano(), FencingToken: a.FencingToken})
+			waitState(t, d, "t1", TaskRunning)
+
+			d.RecordResult("w1", result(a, tt.status, pb.ErrorClass_ERROR_CLASS_HANDLER))
+			if task, _ := d.Tasks().GetTask("t1"); task.State != tt.want {
+				t.Errorf("state = %s, want %s", task.State, tt.want)
+			}
+			if acked, _ := owner.counts(); acked != 1 {
+				t.Errorf("source acked %d times, want 1", acked)
+			}
+			// the finished task no longer counts against the worker
+			if active, _ := sm.GetGlobalLoad(); active != 0 {
+				t.Errorf("worker still counts %d active tasks", active)
+			}
+			if _, buried := d.DeadLetters().Get("t1"); buried != (tt.want != TaskSucceeded) {
+				t.Errorf("dead-lettered = %v", buried)
+			}
+		})
+	}
+}
+
+// loseWorker deregisters w1 with its tasks requeued, then registers it again
+// on a new stream
+func loseWorker(sm *StateManager, d *Dispatcher, stream *fakeStream) *fakeStream {
+	tasks, _ := sm.DeregisterWorker("w1", stream)
+	d.RequeueNow("w1", tasks)
+	next := newFakeStream()
+	sm.RegisterWorker(&pb.RegisterRequest{Hostname: "host-1", MaxConcurrency: 4}, "w1", next, nil)
+	return next
+}
+
+func TestDispatcherRequeue(t *testing.T) {
+	d, sm, stream := newTestDispatcher(t, nil)
+	d.Submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "job"})
+	if first := stream.assignment(t); first.Attempt != 1 {
+		t.Fatalf("first attempt %d, want 1", first.Attempt)
+	}
+
+	stream = loseWorker(sm, d, stream)
+	second := stream.assignment(t)
+	if second.TaskId != "t1" || second.Attempt != 2 {
+		t.Fatalf("requeued %s attempt %d, want t1 attempt 2", second.TaskId, second.Attempt)
+	}
+}
+
+func TestDispatcherLateResult(t *testing.T) {
+	sm := NewStateManager()
+	d := NewDispatcher(sm)
+	d.ReclaimGrace = 0
+	sm.SetWorkerLostHandler(d.Requeue)
+	stream := newFakeStream()
+	sm.RegisterWorker(&pb.RegisterRequest{Hostname: "host-1", MaxConcurrency: 4}, "w1", stream, nil)
+	// no dispatch loop: the requeued task stays pending
+	d.Submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "job"})
+	tasks, _ := d.next(context.Background())
+	if err := d.dispatch(tasks[0]); err != nil {
+		t.Fatal(err)
+	}
+	a := stream.assignment(t)
+
+	sm.UnregisterWorker("w1", stream)
+	if n := d.PendingCount(); n != 1 {
+		t.Fatalf("%d tasks pending after the worker was lost, want 1", n)
+	}
+	// its result still arrives, so the retry is dropped
+	d.RecordResult("w1", result(a, pb.TaskStatus_TASK_STATUS_SUCCEEDED, pb.ErrorClass_ERROR_CLASS_UNSPECIFIED))
+	if n := d.PendingCount(); n != 0 {
+		t.Errorf("%d tasks pending after a late result, want 0", n)
+	}
+}
+
+func TestDispatcherRequeueToSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		nackErr error
+	}{
+		{"redelivered by the source", nil},
+		{"source unreachable", errors.New("channel closed")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, sm, stream := newTestDispatcher(t, nil)
+			owner := &fakeOwner{nackErr: tt.nackErr}
+			if err := d.SubmitFrom(&pb.TaskAssignment{TaskId: "t1", TaskName: "job"}, owner); err != nil {
+				t.Fatal(err)
+			}
+			stream.assignment(t)
+
+			stream = loseWorker(sm, d, stream)
+			if _, nacked := owner.counts(); nacked != 1 {
+				t.Fatalf("source nacked %d times, want 1", nacked)
+			}
+			if tt.nackErr == nil {
+				// the broker delivers the task again; it continues the same record
+				if err := d.SubmitFrom(&pb.TaskAssignment{TaskId: "t1", TaskName: "job"}, owner); err != nil {
+					t.Fatalf("redelivery: %v", err)
+				}
+			}
+			if a := stream.assignment(t); a.Attempt != 2 {
+				t.Errorf("attempt = %d, want 2", a.Attempt)
+			}
+		})
+	}
+}
+
+func TestDispatcherUnschedulable(t *testing.T) {
+	d, sm, _ := newTestDispatcher(t, func(d *Dispatcher) {
+		d.UnschedulableTimeout = 50 * time.Millisecond
+	})
+	gpu := &pb.Resources{Named: map[string]int64{"gpu": 1}}
+
+	// w1 has no GPU at all: the task fails instead of waiting forever
+	owner := &fakeOwner{}
+	if err := d.SubmitFrom(&pb.TaskAssignment{TaskId: "t1", TaskName: "train", Requests: gpu}, owner); err != nil {
+		t.Fatal(err)
+	}
+	waitState(t, d, "t1", TaskFailed)
+	// the dispatch loop buries the task and then acks it to its source
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(5 * time.Millisecond) {
+		if acked, _ := owner.counts(); acked == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("unschedulable task was not acked to its source")
+		}
+	}
+	if _, buried := d.DeadLetters().Get("t1"); !buried {
+		t.Error("unschedulable task was not dead-lettered")
+	}
+
+	// a worker large enough joins before the timeout runs out
+	if err := d.Submit(&pb.TaskAssignment{TaskId: "t2", TaskName: "train", Requests: gpu}); err != nil {
+		t.Fatal(err)
+	}
+	stream := newFakeStream()
+	sm.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4, Capacity: gpu}, "w2", stream, nil)
+	if a := stream.assignment(t); a.TaskId != "t2" {
+		t.Errorf("assigned %s, want t2", a.TaskId)
+	}
+}
+
+func TestDispatcherUnschedulablePlacement(t *testing.T) {
+	d, sm, _ := newTestDispatcher(t, func(d *Dispatcher) {
+		d.UnschedulableTimeout = 50 * time.Millisecond
+	})
+	east := &pb.Placement{NodeSelector: map[string]string{"zone": "us-east"}}
+
+	// w1 has no labels: no worker matches, so the task fails like one too large
+	if err := d.Submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "job", Placement: east}); err != nil {
+		t.Fatal(err)
+	}
+	waitState(t, d, "t1", TaskFailed)
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(5 * time.Millisecond) {
+		if _, buried := d.DeadLetters().Get("t1"); buried {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("task matching no worker was not dead-lettered")
+		}
+	}
+
+	// a matching worker joins before the timeout runs out
+	if err := d.Submit(&pb.TaskAssignment{TaskId: "t2", TaskName: "job", Placement: east}); err != nil {
+		t.Fatal(err)
+	}
+	stream := newFakeStream()
+	sm.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4, Labels: map[string]string{"zone": "us-east"}}, "w2", stream, nil)
+	if a := stream.assignment(t); a.TaskId != "t2" {
+		t.Errorf("assigned %s, want t2", a.TaskId)
+	}
+}
+
+func TestDispatcherDrain(t *testing.T) {
+	// a grace period far beyond the test: deregistered tasks must not wait for it
+	d, sm, stream := newTestDispatcher(t, func(d *Dispatcher) {
+		d.ReclaimGrace = time.Hour
+	})
+	if err := d.Submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "job"}); err != nil {
+		t.Fatal(err)
+	}
+	first := stream.assignment(t)
+	if n, err := sm.SetDraining("w1"); err != nil || n != 1 {
+		t.Fatalf("SetDraining() = %d, %v; want 1 task in flight", n, err)
+	}
+
+	// a draining worker gets no new tasks
+	other := newFakeStream()
+	if err := d.Submit(&pb.TaskAssignment{TaskId: "t2", TaskName: "job"}); err != nil {
+		t.Fatal(err)
+	}
+	sm.RegisterWorker(&pb.RegisterRequest{Hostname: "host-2", MaxConcurrency: 4}, "w2", other, nil)
+	if a := other.assignment(t); a.TaskId != "t2" {
+		t.Fatalf("assigned %s to w2, want t2", a.TaskId)
+	}
+
+	// the deadline passed with t1 unfinished: it moves to w2 right away
+	tasks, ok := sm.DeregisterWorker("w1", stream)
+	if !ok {
+		t.Fatal("draining worker was not deregistered")
+	}
+	d.RequeueNow("w1", tasks)
+	if sm.Registered("w1") {
+		t.Error("deregistered worker still registered")
+	}
+	a := other.assignment(t)
+	if a.TaskId != "t1" || a.Attempt != 2 || a.FencingToken == first.FencingToken {
+		t.Errorf("requeued %s attempt %d token %d, want t1 attempt 2 with a new token", a.TaskId, a.Attempt, a.FencingToken)
+	}
+	select {
+	case msg := <-stream.sent:
+		t.Errorf("sent %v to the drained worker", msg)
+	default:
+	}
+}
+
+func TestDispatcherReclaimGrace(t *testing.T) {
+	tests := []struct {
+		name    string
+		adopted bool
+	}{
+		{"worker comes back", true},
+		{"worker stays away", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, sm, stream := newTestDispatcher(t, func(d *Dispatcher) {
+				d.ReclaimGrace = 100 * time.Millisecond
+			})
+			if err := d.Submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "job"}); err != nil {
+				t.Fatal(err)
+			}
+			a := stream.assignment(t)
+
+			tasks, _ := sm.DeregisterWorker("w1", stream)
+			d.Requeue("w1", tasks)
+			next := newFakeStream()
+			sm.RegisterWorker(&pb.RegisterRequest{Hostname: "host-1", MaxConcurrency: 4}, "w1", next, nil)
+			if tt.adopted {
+				d.Adopt("w1", []*pb.InFlightTask{{Task: a, Started: true, StartTimeUnixNano: time.Now().UnixNano()}})
+			}
+
+			time.Sleep(300 * time.Millisecond)
+			task, _ := d.Tasks().GetTask("t1")
+			select {
+			case msg := <-next.sent:
+				if tt.adopted {
+					t.Errorf("adopted task was sent again: %v", msg)
+				}
+			default:
+				if !tt.adopted {
+					t.Error("unclaimed task was not requeued after the grace period")
+				}
+			}
+			if tt.adopted && (task.State != TaskRunning || task.FencingToken != a.FencingToken) {
+				t.Errorf("adopted task is %s with token %d, want Running with %d", task.State, task.FencingToken, a.FencingToken)
+			}
+		})
+	}
+}
+
+func TestDispatcherRedeliveryOfSettledTask(t *testing.T) {
+	tests := []struct {
+		name    string
+		adopted bool
+	}{
+		{"finished by a late result", false},
+		{"adopted by its worker", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, sm, stream := newTestDispatcher(t, nil)
+			owner := &fakeOwner{}
+			if err := d.SubmitFrom(&pb.TaskAssignment{TaskId: "t1", TaskName: "job"}, owner); err != nil {
+				t.Fatal(err)
+			}
+			a := stream.assignment(t)
+			stream = loseWorker(sm, d, stream)
+
+			if tt.adopted {
+				d.Adopt("w1", []*pb.InFlightTask{{Task: a}})
+				waitState(t, d, "t1", TaskAssigned)
+			} else {
+				d.RecordResult("w1", result(a, pb.TaskStatus_TASK_STATUS_SUCCEEDED, 0))
+				waitState(t, d, "t1", TaskSucceeded)
+			}
+			// the source redelivers the task it was handed back
+			if err := d.SubmitFrom(&pb.TaskAssignment{TaskId: "t1", TaskName: "job"}, owner); err != nil {
+				t.Fatalf("redelivery: %v", err)
+			}
+			if tt.adopted {
+				if acked, _ := owner.counts(); acked != 0 {
+					t.Fatalf("source acked the redelivery of a running task")
+				}
+				d.RecordResult("w1", result(a, pb.TaskStatus_TASK_STATUS_SUCCEEDED, 0))
+				waitState(t, d, "t1", TaskSucceeded)
+			}
+
+			if acked, _ := owner.counts(); acked != 1 {
+				t.Errorf("source acked %d times, want 1", acked)
+			}
+			select {
+			case msg := <-stream.sent:
+				t.Errorf("redelivery was dispatched again: %v", msg)
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestDispatcherCancel(t *testing.T) {
+	d, _, stream := newTestDispatcher(t, nil)
+	if err := d.Submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "job"}); err != nil {
+		t.Fatal(err)
+	}
+	a := stream.assignment(t)
+
+	if _, err := d.Cancel("t1", "no longer needed"); err != nil {
+		t.Fatal(err)
+	}
+	if c := stream.cancellation(t); c.TaskId != "t1" || c.FencingToken != a.FencingToken {
+		t.Errorf("cancellation = %v, want task t1 with token %d", c, a.FencingToken)
+	}
+	// the lease is gone: a heartbeat still listing the attempt stops it again
+	d.RenewLeases("w1", []*pb.TaskLease{{TaskId: "t1", FencingToken: a.FencingToken}})
+	if c := stream.cancellation(t); c.TaskId != "t1" || c.FencingToken != a.FencingToken {
+		t.Errorf("cancellation = %v, want task t1 with token %d again", c, a.FencingToken)
+	}
+	d.RecordResult("w1", result(a, pb.TaskStatus_TASK_STATUS_CANCELLED, pb.ErrorClass_ERROR_CLASS_UNSPECIFIED))
+	if task := waitState(t, d, "t1", TaskCancelled); task.LastError != "no longer needed" {
+		t.Errorf("LastError = %q", task.LastError)
+	}
+	if _, buried := d.DeadLetters().Get("t1"); buried {
+		t.Error("a cancelled task was dead-lettered")
+	}
+}
+
+func TestDispatcherRetryBudget(t *testing.T) {
+	policy := &pb.RetryPolicy{MaxAttempts: 2}
+	d, sm, stream := newTestDispatcher(t, nil)
+	if err := d.Submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "job", RetryPolicy: policy}); err != nil {
+		t.Fatal(err)
+	}
+	stream.assignment(t)
+
+	// lost with its worker: does not count against the budget
+	stream = loseWorker(sm, d, stream)
+	a := stream.assignment(t)
+
+	// first failure, on attempt 2: retried
+	d.RecordResult("w1", result(a, pb.TaskStatus_TASK_STATUS_FAILED, pb.ErrorClass_ERROR_CLASS_HANDLER))
+	a = stream.assignment(t)
+	if a.Attempt != 3 {
+		t.Fatalf("retry attempt = %d, want 3", a.Attempt)
+	}
+
+	// second failure: the budget of two failed attempts is spent
+	d.RecordResult("w1", result(a, pb.TaskStatus_TASK_STATUS_FAILED, pb.ErrorClass_ERROR_CLASS_HANDLER))
+	waitState(t, d, "t1", TaskFailed)
+	letter, ok := d.DeadLetters().Get("t1")
+	if !ok {
+		t.Fatal("task was not dead-lettered")
+	}
+	if len(letter.Failures) != 2 {
+		t.Errorf("dead letter has %d failures, want 2", len(letter.Failures))
+	}
+}
+
+func TestDispatcherPermanentFailure(t *testing.T) {
+	d, _, stream := newTestDispatcher(t, func(d *Dispatcher) {
+		d.DefaultRetryPolicy = &pb.RetryPolicy{MaxAttempts: 5}
+	})
+	if err := d.Submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "job"}); err != nil {
+		t.Fatal(err)
+	}
+	a := stream.assignment(t)
+	d.RecordResult("w1", result(a, pb.TaskStatus_TASK_STATUS_FAILED, pb.ErrorClass_ERROR_CLASS_PERMANENT))
+	waitState(t, d, "t1", TaskFailed)
+}
+
+func TestDispatcherFencing(t *testing.T) {
+	d, sm, stream := newTestDispatcher(t, nil)
+	if err := d.Submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "job"}); err != nil {
+		t.Fatal(err)
+	}
+	zombie := stream.assignment(t)
+	stream = loseWorker(sm, d, stream)
+	current := stream.assignment(t)
+
+	// the superseded attempt reports in; none of it may touch the task
+	d.RecordStarted("w1", &pb.TaskStarted{TaskId: "t1", StartTimeUnixNano: time.Now().UnixNano(), FencingToken: zombie.FencingToken})
+	d.RecordResult("w1", result(zombie, pb.TaskStatus_TASK_STATUS_SUCCEEDED, 0))
+	task, _ := d.Tasks().GetTask("t1")
+	if task.State != TaskAssigned || task.FencingToken != current.FencingToken {
+		t.Fatalf("after the zombie result task is %s with token %d, want Assigned with %d", task.State, task.FencingToken, current.FencingToken)
+	}
+	if w := sm.Workers()[0]; w.ActiveTaskCount != 1 {
+		t.Errorf("zombie result freed the current attempt's slot: %d active", w.ActiveTaskCount)
+	}
+
+	// a heartbeat listing the zombie gets it stopped
+	d.RenewLeases("w1", []*pb.TaskLease{{TaskId: "t1", FencingToken: zombie.FencingToken}})
+	if c := stream.cancellation(t); c.FencingToken != zombie.FencingToken {
+		t.Errorf("cancelled token %d, want the zombie's %d", c.FencingToken, zombie.FencingToken)
+	}
+
+	d.RecordResult("w1", result(current, pb.TaskStatus_TASK_STATUS_SUCCEEDED, 0))
+	waitState(t, d, "t1", TaskSucceeded)
+}
+
+func TestDispatcherLeaseRevoked(t *testing.T) {
+	d, _, stream := newTestDispatcher(t, func(d *Dispatcher) {
+		d.AckTimeout = 10 * time.Millisecond
+	})
+	if err := d.Submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "job"}); err != nil {
+		t.Fatal(err)
+	}
+	first := stream.assignment(t)
+
+	// never acknowledged: revoked, stopped on the worker and dispatched again
+	if c := stream.cancellation(t); c.FencingToken != first.FencingToken {
+		t.Errorf("cancelled token %d, want %d", c.FencingToken, first.FencingToken)
+	}
+	second := stream.assignment(t)
+	if second.Attempt != 2 || second.FencingToken == first.FencingToken {
+		t.Fatalf("redispatched attempt %d token %d", second.Attempt, second.FencingToken)
+	}
+
+	// an acknowledged attempt keeps its lease
+	d.RecordAccepted("w1", &pb.TaskAccepted{TaskId: "t1", FencingToken: second.FencingToken})
+	time.Sleep(2 * leaseCheckInterval)
+	if task, _ := d.Tasks().GetTask("t1"); task.FencingToken != second.FencingToken {
+		t.Errorf("acknowledged attempt was revoked: token %d, want %d", task.FencingToken, second.FencingToken)
+	}
+}
+
+func TestDispatcherExpiryRacingResult(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(d *Dispatcher)
+		timeout   int64
+		wait      time.Duration
+	}{
+		{"timeout watch", func(d *Dispatcher) { d.TimeoutGrace = 0 }, 20, 100 * time.Millisecond},
+		{"lease revoke", func(d *Dispatcher) { d.AckTimeout = 10 * time.Millisecond }, 0, leaseCheckInterval + 100*time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, sm, stream := newTestDispatcher(t, tt.configure)
+			if err := d.Submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "job", TimeoutMillis: tt.timeout}); err != nil {
+				t.Fatal(err)
+			}
+			a := stream.assignment(t)
+			// a result is being recorded: it already took the attempt's slot
+			if _, held := sm.RemoveInFlight("w1", "t1"); !held {
+				t.Fatal("attempt not in flight")
+			}
+
+			time.Sleep(tt.wait)
+			select {
+			case msg := <-stream.sent:
+				t.Fatalf("attempt taken by a result was expired as well: %v", msg)
+			default:
+			}
+			if task, _ := d.Tasks().GetTask("t1"); task.State != TaskAssigned || task.FencingToken != a.FencingToken {
+				t.Errorf("task is %s with token %d, want Assigned with %d", task.State, task.FencingToken, a.FencingToken)
+			}
+			d.mu.Lock()
+			failures := len(d.failures["t1"])
+			d.mu.Unlock()
+			if failures != 0 {
+				t.Errorf("%d failures recorded, want 0", failures)
+			}
+		})
+	}
+}
+
+func TestDispatcherDuplicateSubmit(t *testing.T) {
+	d, _, stream := newTestDispatcher(t, nil)
+	if err := d.Submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "job", IdempotencyKey: "k"}); err != nil {
+		t.Fatal(err)
+	}
+	stream.assignment(t)
+	if err := d.Submit(&pb.TaskAssignment{TaskId: "t2", TaskName: "job", IdempotencyKey: "k"}); !errors.Is(err, ErrDuplicateTask) {
+		t.Errorf("duplicate Submit = %v, want ErrDuplicateTask", err)
+	}
+	if n := d.PendingCount(); n != 0 {
+		t.Errorf("%d tasks pending after a duplicate", n)
+	}
+}
+
+// fakeSource is a TaskSource handing out the tasks sent on its channel
+type fakeSource struct {
+	fakeOwner
+	tasks chan *pb.TaskAssignment
+}
+
+func (s *fakeSource) Next(ctx context.Context) (*pb.TaskAssignment, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case task := <-s.tasks:
+		return task, nil
+	}
+}
+
+func TestConsumeRedelivery(t *testing.T) {
+	tests := []struct {
+		name    string
+		restart bool // the Master restarted and its worker reported the task
+	}{
+		{"source reconnected", false},
+		{"master restarted", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, _, stream := newTestDispatcher(t, nil)
+			src := &fakeSource{tasks: make(chan *pb.TaskAssignment)}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go d.Consume(ctx, src)
+
+			var a *pb.TaskAssignment
+			if tt.restart {
+				a = &pb.TaskAssignment{TaskId: "t1", TaskName: "job", Attempt: 1, FencingToken: 1}
+				d.Adopt("w1", []*pb.InFlightTask{{Task: a}})
+			} else {
+				src.tasks <- &pb.TaskAssignment{TaskId: "t1", TaskName: "job"}
+				a = stream.assignment(t)
+			}
+			// the delivery the task is running on
+			src.tasks <- &pb.TaskAssignment{TaskId: "t1", TaskName: "job"}
+			src.tasks <- &pb.TaskAssignment{TaskId: "t2", TaskName: "job"} // Consume handled t1
+			stream.assignment(t)
+			if acked, _ := src.counts(); acked != 0 {
+				t.Fatal("redelivery of an unfinished task was acked")
+			}
+
+			d.RecordResult("w1", result(a, pb.TaskStatus_TASK_STATUS_SUCCEEDED, 0))
+			waitState(t, d, "t1", TaskSucceeded)
+			if acked, _ := src.counts(); acked != 1 {
+				t.Fatalf("source acked %d times, want 1", acked)
+			}
+
+			// a redelivery of the finished task is acked right away
+			src.tasks <- &pb.TaskAssignment{TaskId: "t1", TaskName: "job"}
+			src.tasks <- &pb.TaskAssignment{TaskId: "t3", TaskName: "job"}
+			stream.assignment(t)
+			if acked, _ := src.counts(); acked != 2 {
+				t.Errorf("source acked %d times, want 2", acked)
+			}
+		})
+	}
+}
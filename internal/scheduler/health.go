@@ -0,0 +1,191 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// HealthState is the Master's opinion about a worker's liveness
+type HealthState int
+
+const (
+	// Healthy workers receive new tasks
+	Healthy HealthState = iota
+	// Suspect workers missed a heartbeat; they keep their stream but are
+	// skipped by SelectWorker
+	Suspect
+	// Dead workers are evicted by the reaper: stream closed, worker unregistered
+	Dead
+)
+
+func (h HealthState) String() string {
+	switch h {
+	case Healthy:
+		return "Healthy"
+	case Suspect:
+		return "Suspect"
+	case Dead:
+		return "Dead"
+	default:
+		return "Unknown"
+	}
+}
+
+// HealthModel selects how silence is turned into a HealthState
+type HealthModel int
+
+const (
+	// HealthModelTimeout uses the fixed SuspectAfter/DeadAfter thresholds
+	HealthModelTimeout HealthModel = iota
+	// HealthModelPhi uses the phi-accrual suspicion level against PhiSuspect/PhiDead
+	HealthModelPhi
+	// HealthModelKeepalive uses each worker's adaptive keepalive deadline
+	// (Deadline); it falls back to HealthModelTimeout if Deadline is nil
+	HealthModelKeepalive
+)
+
+// HealthConfig holds the heartbeat thresholds used to classify workers
+type HealthConfig struct {
+	Model HealthModel
+
+	// HealthModelTimeout
+	SuspectAfter time.Duration // silence after which a worker becomes Suspect
+	DeadAfter    time.Duration // silence after which a worker is evicted
+
+	// HealthModelPhi
+	PhiSuspect float64 // phi at which a worker becomes Suspect
+	PhiDead    float64 // phi at which a worker is evicted
+
+	// HealthModelKeepalive: Deadline returns how long a worker may stay
+	// silent (no heartbeat, no Pong), i.e. keepalive_time + keepalive_timeout
+	// from the keepalive controller. The worker becomes Suspect after one
+	// deadline and is evicted after DeadlineMisses.
+	Deadline       func(workerID string) time.Duration
+	DeadlineMisses int
+
+	// PhiWeight down-weights workers with rising phi in the Strategy (with
+	// any model): each unit of phi counts as PhiWeight extra tasks.
+	// 0 disables it.
+	PhiWeight float64
+	Phi       PhiConfig
+
+	CheckInterval time.Duration // how often the reaper scans workers
+}
+
+// DefaultHealthConfig matches the 5s worker heartbeat: Suspect after one
+// missed heartbeat, Dead after three
+func DefaultHealthConfig() HealthConfig {
+	return HealthConfig{
+		Model:          HealthModelTimeout,
+		SuspectAfter:   7 * time.Second,
+		DeadAfter:      15 * time.Second,
+		PhiSuspect:     3,
+		PhiDead:        8,
+		DeadlineMisses: 2,
+		PhiWeight:      1,
+		Phi:            DefaultPhiConfig(),
+		CheckInterval:  1 * time.Second,
+	}
+}
+
+// SetHealthConfig replaces the heartbeat thresholds
+func (sm *StateManager) SetHealthConfig(cfg HealthConfig) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.health = cfg
+}
+
+// healthOf classifies a worker by how long it has been silent, against fixed
+// thresholds or, with HealthModelKeepalive, against its adaptive deadline;
+// with HealthModelPhi by its suspicion level.
+// Callers must hold sm.mu.
+func (sm *StateManager) healthOf(ws *WorkerStats, now time.Time) HealthState {
+	if sm.health.Model == HealthModelPhi {
+		phi := ws.PhiDetector.Phi(now)
+		switch {
+		case phi >= sm.health.PhiDead:
+			return Dead
+		case phi >= sm.health.PhiSuspect:
+			return Suspect
+		default:
+			return Healthy
+		}
+	}
+
+	silence, suspectAfter, deadAfter := sm.silenceOf(ws, now)
+	switch {
+	case silence >= deadAfter:
+		return Dead
+	case silence >= suspectAfter:
+		return Suspect
+	default:
+		return Healthy
+	}
+}
+
+// silenceOf returns how long a worker has been silent and the silences after
+// which it is Suspect and Dead: its adaptive deadline with
+// HealthModelKeepalive, the fixed thresholds otherwise.
+// Callers must hold sm.mu.
+func (sm *StateManager) silenceOf(ws *WorkerStats, now time.Time) (silence, suspectAfter, deadAfter time.Duration) {
+	if sm.health.Model == HealthModelKeepalive && sm.health.Deadline != nil {
+		// Pongs answer the prober's pings, so they prove liveness as well
+		last := ws.LastHeartbeat
+		if ws.LastPong.After(last) {
+			last = ws.LastPong
+		}
+		deadline := sm.health.Deadline(ws.ID)
+		return now.Sub(last), deadline, deadline * time.Duration(max(sm.health.DeadlineMisses, 1))
+	}
+	return now.Sub(ws.LastHeartbeat), sm.health.SuspectAfter, sm.health.DeadAfter
+}
+
+// StartReaper launches the background goroutine that refreshes every
+// worker's Health and evicts Dead ones. It stops when ctx is cancelled.
+func (sm *StateManager) StartReaper(ctx context.Context) {
+	go func() {
+		for {
+			sm.mu.RLock()
+			interval := sm.health.CheckInterval
+			sm.mu.RUnlock()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+				sm.reap(time.Now())
+			}
+		}
+	}()
+}
+
+// reap updates health states and evicts Dead workers
+func (sm *StateManager) reap(now time.Time) {
+	var evicted []*WorkerStats
+
+	sm.mu.Lock()
+	for id, ws := range sm.workers {
+		ws.Phi = ws.PhiDetector.Phi(now)
+		state := sm.healthOf(ws, now)
+		if state != ws.Health {
+			log.Printf("[StateManager] Worker %s is now %s (last heartbeat %v ago, phi %.2f)", id, state, now.Sub(ws.LastHeartbeat).Round(time.Millisecond), ws.Phi)
+			ws.Health = state
+		}
+		if state == Dead {
+			delete(sm.workers, id)
+			evicted = append(evicted, ws)
+		}
+	}
+	remaining := len(sm.workers)
+	sm.mu.Unlock()
+
+	// close streams outside the lock; this makes the Connect handler return
+	for _, ws := range evicted {
+		if ws.closeStream != nil {
+			ws.closeStream()
+		}
+		log.Printf("[StateManager] Worker %s evicted. Total workers: %d", ws.ID, remaining)
+		sm.workerLost(ws)
+	}
+}
@@ -0,0 +1,152 @@
+package scheduler
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+func TestHealthTimeoutModel(t *testing.T) {
+	tests := []struct {
+		name    string
+		silence time.Duration
+		want    HealthState
+		evicted bool
+	}{
+		{"fresh heartbeat", time.Second, Healthy, false},
+		{"one missed heartbeat", 8 * time.Second, Suspect, false},
+		{"silent", 16 * time.Second, Dead, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewStateManager()
+			var closed atomic.Bool
+			sm.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4}, "w1", newFakeStream(), func() { closed.Store(true) })
+			sm.mu.RLock()
+			ws := sm.workers["w1"]
+			sm.mu.RUnlock()
+
+			sm.reap(ws.LastHeartbeat.Add(tt.silence))
+
+			sm.mu.RLock()
+			_, registered := sm.workers["w1"]
+			sm.mu.RUnlock()
+			if ws.Health != tt.want {
+				t.Errorf("health = %s, want %s", ws.Health, tt.want)
+			}
+			if registered == tt.evicted || closed.Load() != tt.evicted {
+				t.Errorf("registered %v, stream closed %v; want evicted %v", registered, closed.Load(), tt.evicted)
+			}
+		})
+	}
+}
+
+func TestHealthKeepaliveModel(t *testing.T) {
+	tests := []struct {
+		name    string
+		silence time.Duration
+		pong    time.Duration // pong this long after the last heartbeat, 0 for none
+		want    HealthState
+	}{
+		{"within the deadline", 2 * time.Second, 0, Healthy},
+		{"one deadline", 4 * time.Second, 0, Suspect},
+		{"two deadlines", 7 * time.Second, 0, Dead},
+		// the last answered ping counts as contact
+		{"recent pong", 7 * time.Second, 5 * time.Second, Healthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewStateManager()
+			cfg := DefaultHealthConfig()
+			cfg.Model = HealthModelKeepalive
+			cfg.Deadline = func(workerID string) time.Duration { return 3 * time.Second }
+			sm.SetHealthConfig(cfg)
+			sm.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4}, "w1", newFakeStream(), nil)
+			sm.mu.Lock()
+			ws := sm.workers["w1"]
+			if tt.pong > 0 {
+				ws.LastPong = ws.LastHeartbeat.Add(tt.pong)
+			}
+			sm.mu.Unlock()
+
+			sm.reap(ws.LastHeartbeat.Add(tt.silence))
+			if ws.Health != tt.want {
+				t.Errorf("health = %s, want %s", ws.Health, tt.want)
+			}
+			if sm.Registered("w1") == (tt.want == Dead) {
+				t.Errorf("registered = %v with health %s", sm.Registered("w1"), ws.Health)
+			}
+		})
+	}
+}
+
+func TestReaperHandsOverInFlightTasks(t *testing.T) {
+	sm := NewStateManager()
+	var lost []*pb.TaskAssignment
+	sm.SetWorkerLostHandler(func(workerID string, tasks []*pb.TaskAssignment) {
+		lost = append(lost, tasks...)
+	})
+	sm.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4}, "w1", newFakeStream(), nil)
+	sm.AddInFlight("w1", &pb.TaskAssignment{TaskId: "t1"})
+
+	sm.reap(time.Now().Add(time.Minute))
+	if len(lost) != 1 || lost[0].TaskId != "t1" {
+		t.Errorf("lost tasks = %v, want t1", lost)
+	}
+}
+
+func TestSelectWorkerSkipsSuspect(t *testing.T) {
+	sm := NewStateManager()
+	sm.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4}, "w1", newFakeStream(), nil)
+	sm.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4}, "w2", newFakeStream(), nil)
+	sm.UpdateWorkerStatus("w1", &pb.StatusUpdate{ActiveTaskCount: 3, Periodic: true})
+
+	// w2 falls silent; w1 is busier but the only Healthy one
+	sm.mu.Lock()
+	sm.workers["w2"].LastHeartbeat = time.Now().Add(-10 * time.Second)
+	sm.mu.Unlock()
+
+	if id, _, err := sm.SelectWorker(TaskSpec{ID: "t1"}); err != nil || id != "w1" {
+		t.Errorf("SelectWorker() = %q, %v; want w1", id, err)
+	}
+}
+
+func TestSelectWorkerSkipsDraining(t *testing.T) {
+	sm := NewStateManager()
+	sm.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4}, "w1", newFakeStream(), nil)
+	// a worker that reconnects mid-drain says so in its registration
+	sm.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4, Draining: true}, "w2", newFakeStream(), nil)
+	sm.UpdateWorkerStatus("w1", &pb.StatusUpdate{ActiveTaskCount: 3, Periodic: true})
+
+	if id, _, err := sm.SelectWorker(TaskSpec{ID: "t1"}); err != nil || id != "w1" {
+		t.Errorf("SelectWorker() = %q, %v; want w1", id, err)
+	}
+	if _, err := sm.SetDraining("w1"); err != nil {
+		t.Fatal(err)
+	}
+	if id, _, err := sm.SelectWorker(TaskSpec{ID: "t1"}); err == nil {
+		t.Errorf("SelectWorker() = %q with every worker draining, want an error", id)
+	}
+	if _, err := sm.SetDraining("w3"); err == nil {
+		t.Error("SetDraining of an unknown worker succeeded")
+	}
+}
+
+func TestReplacedSessionStaysRegistered(t *testing.T) {
+	sm := NewStateManager()
+	var closed atomic.Bool
+	sm.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4}, "w1", newFakeStream(), func() { closed.Store(true) })
+
+	// a reconnect closes the old session, but the worker is still there
+	sm.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4}, "w1", newFakeStream(), nil)
+	if !closed.Load() || !sm.Registered("w1") {
+		t.Errorf("old stream closed %v, registered %v; want both", closed.Load(), sm.Registered("w1"))
+	}
+
+	sm.reap(time.Now().Add(time.Minute))
+	if sm.Registered("w1") {
+		t.Error("evicted worker still registered")
+	}
+}
@@ -0,0 +1,156 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+const (
+	// defaultAckTimeout is how long a worker has to acknowledge an assignment
+	defaultAckTimeout = 10 * time.Second
+	// defaultLeaseDuration spans several worker heartbeats, so one lost
+	// StatusUpdate does not cost a lease
+	defaultLeaseDuration = 30 * time.Second
+	// leaseCheckInterval is how often expired leases are looked for
+	leaseCheckInterval = time.Second
+)
+
+// lease is a worker's claim on one attempt of a task. Until the worker
+// acknowledges the assignment, expires is the ack deadline; afterwards every
+// heartbeat listing the attempt pushes it out by LeaseDuration.
+type lease struct {
+	workerID string
+	task     *pb.TaskAssignment
+	token    uint64
+	accepted bool
+	expires  time.Time
+}
+
+// nextToken returns a fencing token for a new attempt. Tokens start at the
+// Master's start time in nanoseconds, so they keep increasing across
+// restarts and never collide with tokens workers still hold.
+func (d *Dispatcher) nextToken() uint64 {
+	return d.tokens.Add(1)
+}
+
+// grantLease starts the lease of an attempt that is about to be sent
+func (d *Dispatcher) grantLease(workerID string, task *pb.TaskAssignment, accepted bool) {
+	l := &lease{
+		workerID: workerID,
+		task:     task,
+		token:    task.FencingToken,
+		accepted: accepted,
+		expires:  time.Now().Add(d.AckTimeout),
+	}
+	if accepted {
+		l.expires = time.Now().Add(d.LeaseDuration)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.leases[task.TaskId] = l
+}
+
+// RecordAccepted turns an assignment's ack deadline into a renewable lease
+func (d *Dispatcher) RecordAccepted(workerID string, accepted *pb.TaskAccepted) {
+	if !d.renew(workerID, accepted.TaskId, accepted.FencingToken) {
+		log.Printf("[Dispatcher] Ignoring ack of task %s from worker %s: no lease for token %d", accepted.TaskId, workerID, accepted.FencingToken)
+	}
+}
+
+// RenewLeases extends the leases of the attempts a worker reported in a
+// heartbeat. Attempts the Master no longer leases to the worker (revoked,
+// superseded, finished) are zombies and the worker is told to stop them.
+func (d *Dispatcher) RenewLeases(workerID string, leases []*pb.TaskLease) {
+	for _, r := range leases {
+		if d.renew(workerID, r.TaskId, r.FencingToken) {
+			continue
+		}
+		if t, ok := d.tasks.GetTask(r.TaskId); ok && t.State.Terminal() && t.State != TaskCancelled && t.FencingToken == r.FencingToken {
+			// finished with this very attempt; the heartbeat raced the result.
+			// A cancelled attempt still running is stopped again.
+			continue
+		}
+		d.cancelOnWorker(workerID, r.TaskId, r.FencingToken, "lease revoked")
+	}
+}
+
+// renew extends a lease and marks it accepted; it reports whether the
+// worker holds the lease for that token
+func (d *Dispatcher) renew(workerID, taskID string, token uint64) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	l, ok := d.leases[taskID]
+	if !ok || l.workerID != workerID || l.token != token {
+		return false
+	}
+	l.accepted = true
+	l.expires = time.Now().Add(d.LeaseDuration)
+	return true
+}
+
+// reapLeases revokes expired leases until ctx is cancelled
+func (d *Dispatcher) reapLeases(ctx context.Context) {
+	ticker := time.NewTicker(leaseCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		var expired []*lease
+		d.mu.Lock()
+		for id, l := range d.leases {
+			if now.After(l.expires) {
+				expired = append(expired, l)
+				delete(d.leases, id)
+			}
+		}
+		d.mu.Unlock()
+
+		for _, l := range expired {
+			d.revoke(l)
+		}
+	}
+}
+
+// revoke takes an attempt away from its worker and requeues the task. Leases
+// whose attempt already ended (result, requeue, cancellation) are dropped.
+func (d *Dispatcher) revoke(l *lease) {
+	t, ok := d.tasks.GetTask(l.task.TaskId)
+	if !ok || t.FencingToken != l.token || t.WorkerID != l.workerID ||
+		(t.State != TaskAssigned && t.State != TaskRunning) {
+		return
+	}
+
+	if _, held := d.sm.RemoveInFlight(l.workerID, l.task.TaskId); !held {
+		// a result (or the timeout watch) took the attempt in the meantime
+		return
+	}
+	d.sm.DecrementActiveTasks(l.workerID)
+
+	reason := "lease expired"
+	if !l.accepted {
+		reason = "assignment not acknowledged"
+	}
+	log.Printf("[Dispatcher] Revoking task %s from worker %s (token %d): %s", l.task.TaskId, l.workerID, l.token, reason)
+	d.cancelOnWorker(l.workerID, l.task.TaskId, l.token, reason)
+	d.requeue(l.workerID, []*pb.TaskAssignment{l.task}, 0, reason+" on worker "+l.workerID)
+}
+
+// dropLease forgets the lease of an attempt that ended
+func (d *Dispatcher) dropLease(taskID string, token uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if l, ok := d.leases[taskID]; ok && l.token == token {
+		delete(d.leases, taskID)
+	}
+}
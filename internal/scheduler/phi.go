@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"math"
+	"time"
+
+	"github.com/YilinZhang0101/SwiftScheduler/internal/ring"
+)
+
+// PhiConfig holds the phi-accrual detector settings
+type PhiConfig struct {
+	WindowSize int // heartbeat inter-arrival samples kept per worker
+	// MinStdDev keeps phi from exploding when heartbeats are very regular
+	MinStdDev time.Duration
+	// AcceptablePause is added to the mean interval to tolerate GC pauses etc.
+	AcceptablePause time.Duration
+	// FirstHeartbeatEstimate seeds the window so a new worker is not suspected
+	// before it has sent enough heartbeats; use the worker heartbeat period
+	FirstHeartbeatEstimate time.Duration
+}
+
+// DefaultPhiConfig matches the 5s worker heartbeat
+func DefaultPhiConfig() PhiConfig {
+	return PhiConfig{
+		WindowSize:             100,
+		MinStdDev:              500 * time.Millisecond,
+		AcceptablePause:        0,
+		FirstHeartbeatEstimate: 5 * time.Second,
+	}
+}
+
+// PhiAccrual is a phi-accrual failure detector (Hayashibara et al.) for one
+// worker. Instead of a binary alive/dead answer it returns phi, the suspicion
+// level: phi = -log10(P(a heartbeat arrives later than now)). phi 1 means a
+// 10% chance the worker is still fine, phi 3 means 0.1%, and so on.
+// It is not thread-safe; the StateManager guards it.
+type PhiAccrual struct {
+	cfg       PhiConfig
+	intervals *ring.Ring[float64] // inter-arrival times in seconds
+
+	lastArrival  time.Time // last message of any kind
+	lastPeriodic time.Time // last periodic heartbeat, base of the intervals
+}
+
+// NewPhiAccrual constructs a detector that considers now the first arrival
+func NewPhiAccrual(cfg PhiConfig, now time.Time) *PhiAccrual {
+	if cfg.WindowSize <= 0 {
+		cfg.WindowSize = DefaultPhiConfig().WindowSize
+	}
+	d := &PhiAccrual{
+		cfg:          cfg,
+		intervals:    ring.New[float64](cfg.WindowSize),
+		lastArrival:  now,
+		lastPeriodic: now,
+	}
+
+	// seed with the expected interval (mean) and +/- a quarter of it (std)
+	if est := cfg.FirstHeartbeatEstimate.Seconds(); est > 0 {
+		d.intervals.Add(est - est/4)
+		d.intervals.Add(est + est/4)
+	}
+	return d
+}
+
+// Heartbeat records an arrival. Only periodic heartbeats feed the interval
+// distribution; event-driven updates come at irregular times but still prove
+// the worker is alive.
+func (d *PhiAccrual) Heartbeat(now time.Time, periodic bool) {
+	if periodic {
+		d.intervals.Add(now.Sub(d.lastPeriodic).Seconds())
+		d.lastPeriodic = now
+	}
+	d.lastArrival = now
+}
+
+// Phi returns the suspicion level at time now
+func (d *PhiAccrual) Phi(now time.Time) float64 {
+	mean, std := ring.Mean(d.intervals), ring.Std(d.intervals)
+	mean += d.cfg.AcceptablePause.Seconds()
+	if min := d.cfg.MinStdDev.Seconds(); std < min {
+		std = min
+	}
+	elapsed := now.Sub(d.lastArrival).Seconds()
+
+	// logistic approximation of the normal CDF, as used by Akka/Cassandra
+	y := (elapsed - mean) / std
+	e := math.Exp(-y * (1.5976 + 0.070566*y*y))
+	if elapsed > mean {
+		return -math.Log10(e / (1 + e))
+	}
+	return -math.Log10(1 - 1/(1+e))
+}
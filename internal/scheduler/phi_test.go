@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+func TestPhiAccrual(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	cfg := PhiConfig{WindowSize: 20, MinStdDev: 100 * time.Millisecond, FirstHeartbeatEstimate: time.Second}
+
+	tests := []struct {
+		name    string
+		beats   int           // periodic heartbeats sent every second after start
+		silence time.Duration // time since the last heartbeat
+		min     float64
+		max     float64
+	}{
+		{"just heard from", 10, 0, 0, 0.1},
+		{"on schedule", 10, time.Second, 0, 1},
+		{"slightly late", 10, 1200 * time.Millisecond, 0.5, 4},
+		{"long silence", 10, 3 * time.Second, 8, 1e9},
+		{"new worker on schedule", 0, time.Second, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewPhiAccrual(cfg, start)
+			now := start
+			for i := 0; i < tt.beats; i++ {
+				now = now.Add(time.Second)
+				d.Heartbeat(now, true)
+			}
+			if phi := d.Phi(now.Add(tt.silence)); phi < tt.min || phi > tt.max {
+				t.Errorf("phi = %.3f, want between %v and %v", phi, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestPhiAccrualRises(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	d := NewPhiAccrual(DefaultPhiConfig(), start)
+	now := start
+	for i := 0; i < 10; i++ {
+		now = now.Add(5 * time.Second)
+		d.Heartbeat(now, true)
+	}
+
+	last := d.Phi(now)
+	for s := time.Second; s <= 15*time.Second; s += time.Second {
+		phi := d.Phi(now.Add(s))
+		if phi < last {
+			t.Fatalf("phi fell from %.3f to %.3f after %v of silence", last, phi, s)
+		}
+		last = phi
+	}
+}
+
+func TestPhiAccrualNonPeriodic(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	d := NewPhiAccrual(PhiConfig{MinStdDev: 100 * time.Millisecond, FirstHeartbeatEstimate: time.Second}, start)
+
+	// an event-driven update resets the silence but not the interval estimate
+	late := start.Add(3 * time.Second)
+	before := d.Phi(late)
+	d.Heartbeat(late, false)
+	if after := d.Phi(late); after >= before {
+		t.Errorf("phi after an update = %.3f, want below %.3f", after, before)
+	}
+	if d.intervals.Len() != 2 {
+		t.Errorf("an update fed %d intervals, want only the 2 seeds", d.intervals.Len())
+	}
+}
+
+func TestHealthPhiModel(t *testing.T) {
+	tests := []struct {
+		name    string
+		silence time.Duration
+		want    HealthState
+	}{
+		{"on schedule", 5 * time.Second, Healthy},
+		{"late", 7 * time.Second, Suspect},
+		{"silent", 12 * time.Second, Dead},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewStateManager()
+			cfg := DefaultHealthConfig()
+			cfg.Model = HealthModelPhi
+			sm.SetHealthConfig(cfg)
+			sm.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4}, "w1", newFakeStream(), nil)
+
+			sm.mu.Lock()
+			ws := sm.workers["w1"]
+			start := ws.LastHeartbeat
+			for i := 1; i <= 10; i++ {
+				ws.PhiDetector.Heartbeat(start.Add(time.Duration(i)*5*time.Second), true)
+			}
+			last := start.Add(50 * time.Second)
+			ws.LastHeartbeat = last
+			got := sm.healthOf(ws, last.Add(tt.silence))
+			sm.mu.Unlock()
+
+			if got != tt.want {
+				t.Errorf("health = %s (phi %.2f), want %s", got, ws.PhiDetector.Phi(last.Add(tt.silence)), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// ErrInvalidPlacement is returned for tasks whose placement cannot be evaluated
+var ErrInvalidPlacement = errors.New("invalid placement")
+
+// ValidatePlacement checks that every expression of p is well-formed.
+// A nil placement is valid and matches every worker.
+func ValidatePlacement(p *pb.Placement) error {
+	if p == nil {
+		return nil
+	}
+	for k := range p.NodeSelector {
+		if k == "" {
+			return fmt.Errorf("%w: node_selector has an empty key", ErrInvalidPlacement)
+		}
+	}
+	for _, list := range [][]*pb.LabelExpression{p.Affinity, p.AntiAffinity} {
+		for _, e := range list {
+			if err := validateExpression(e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateExpression(e *pb.LabelExpression) error {
+	if e.Key == "" {
+		return fmt.Errorf("%w: expression has an empty key", ErrInvalidPlacement)
+	}
+	switch e.Operator {
+	case pb.LabelOperator_LABEL_OPERATOR_IN, pb.LabelOperator_LABEL_OPERATOR_NOT_IN:
+		if len(e.Values) == 0 {
+			return fmt.Errorf("%w: %s %s needs at least one value", ErrInvalidPlacement, e.Key, e.Operator)
+		}
+	case pb.LabelOperator_LABEL_OPERATOR_EXISTS, pb.LabelOperator_LABEL_OPERATOR_DOES_NOT_EXIST:
+		if len(e.Values) > 0 {
+			return fmt.Errorf("%w: %s %s takes no values", ErrInvalidPlacement, e.Key, e.Operator)
+		}
+	default:
+		return fmt.Errorf("%w: %s has unknown operator %s", ErrInvalidPlacement, e.Key, e.Operator)
+	}
+	return nil
+}
+
+// MatchesPlacement reports whether a worker with the given labels may run a
+// task with placement p
+func MatchesPlacement(labels map[string]string, p *pb.Placement) bool {
+	if p == nil {
+		return true
+	}
+	for k, v := range p.NodeSelector {
+		if got, ok := labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	for _, e := range p.Affinity {
+		if !matchesExpression(labels, e) {
+			return false
+		}
+	}
+	for _, e := range p.AntiAffinity {
+		if matchesExpression(labels, e) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesExpression(labels map[string]string, e *pb.LabelExpression) bool {
+	v, ok := labels[e.Key]
+	switch e.Operator {
+	case pb.LabelOperator_LABEL_OPERATOR_IN:
+		return ok && slices.Contains(e.Values, v)
+	case pb.LabelOperator_LABEL_OPERATOR_NOT_IN:
+		return !ok || !slices.Contains(e.Values, v)
+	case pb.LabelOperator_LABEL_OPERATOR_EXISTS:
+		return ok
+	case pb.LabelOperator_LABEL_OPERATOR_DOES_NOT_EXIST:
+		return !ok
+	default:
+		return false
+	}
+}
@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+func expr(key string, op pb.LabelOperator, values ...string) *pb.LabelExpression {
+	return &pb.LabelExpression{Key: key, Operator: op, Values: values}
+}
+
+func TestMatchesPlacement(t *testing.T) {
+	labels := map[string]string{"zone": "us-east", "gpu": "true"}
+
+	tests := []struct {
+		name      string
+		placement *pb.Placement
+		want      bool
+	}{
+		{"nil placement", nil, true},
+		{"empty placement", &pb.Placement{}, true},
+		{"selector match", &pb.Placement{NodeSelector: map[string]string{"zone": "us-east"}}, true},
+		{"selector value mismatch", &pb.Placement{NodeSelector: map[string]string{"zone": "eu-west"}}, false},
+		{"selector missing key", &pb.Placement{NodeSelector: map[string]string{"pool": "batch"}}, false},
+		{"in match", &pb.Placement{Affinity: []*pb.LabelExpression{expr("zone", pb.LabelOperator_LABEL_OPERATOR_IN, "eu-west", "us-east")}}, true},
+		{"in mismatch", &pb.Placement{Affinity: []*pb.LabelExpression{expr("zone", pb.LabelOperator_LABEL_OPERATOR_IN, "eu-west")}}, false},
+		{"in missing key", &pb.Placement{Affinity: []*pb.LabelExpression{expr("pool", pb.LabelOperator_LABEL_OPERATOR_IN, "batch")}}, false},
+		{"not in match", &pb.Placement{Affinity: []*pb.LabelExpression{expr("zone", pb.LabelOperator_LABEL_OPERATOR_NOT_IN, "eu-west")}}, true},
+		{"not in missing key", &pb.Placement{Affinity: []*pb.LabelExpression{expr("pool", pb.LabelOperator_LABEL_OPERATOR_NOT_IN, "batch")}}, true},
+		{"exists", &pb.Placement{Affinity: []*pb.LabelExpression{expr("gpu", pb.LabelOperator_LABEL_OPERATOR_EXISTS)}}, true},
+		{"does not exist", &pb.Placement{Affinity: []*pb.LabelExpression{expr("gpu", pb.LabelOperator_LABEL_OPERATOR_DOES_NOT_EXIST)}}, false},
+		{"anti-affinity hit", &pb.Placement{AntiAffinity: []*pb.LabelExpression{expr("gpu", pb.LabelOperator_LABEL_OPERATOR_IN, "true")}}, false},
+		{"anti-affinity miss", &pb.Placement{AntiAffinity: []*pb.LabelExpression{expr("gpu", pb.LabelOperator_LABEL_OPERATOR_IN, "false")}}, true},
+		{"all must hold", &pb.Placement{
+			NodeSelector: map[string]string{"zone": "us-east"},
+			Affinity:     []*pb.LabelExpression{expr("pool", pb.LabelOperator_LABEL_OPERATOR_EXISTS)},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchesPlacement(labels, tt.placement); got != tt.want {
+				t.Errorf("MatchesPlacement() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePlacement(t *testing.T) {
+	tests := []struct {
+		name      string
+		placement *pb.Placement
+		valid     bool
+	}{
+		{"nil", nil, true},
+		{"well-formed", &pb.Placement{
+			NodeSelector: map[string]string{"zone": "us-east"},
+			Affinity:     []*pb.LabelExpression{expr("gpu", pb.LabelOperator_LABEL_OPERATOR_EXISTS)},
+			AntiAffinity: []*pb.LabelExpression{expr("pool", pb.LabelOperator_LABEL_OPERATOR_NOT_IN, "spot")},
+		}, true},
+		{"empty selector key", &pb.Placement{NodeSelector: map[string]string{"": "x"}}, false},
+		{"empty expression key", &pb.Placement{Affinity: []*pb.LabelExpression{expr("", pb.LabelOperator_LABEL_OPERATOR_EXISTS)}}, false},
+		{"in without values", &pb.Placement{Affinity: []*pb.LabelExpression{expr("zone", pb.LabelOperator_LABEL_OPERATOR_IN)}}, false},
+		{"exists with values", &pb.Placement{AntiAffinity: []*pb.LabelExpression{expr("zone", pb.LabelOperator_LABEL_OPERATOR_EXISTS, "x")}}, false},
+		{"unknown operator", &pb.Placement{Affinity: []*pb.LabelExpression{expr("zone", pb.LabelOperator_LABEL_OPERATOR_UNSPECIFIED, "x")}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePlacement(tt.placement)
+			if tt.valid && err != nil {
+				t.Errorf("ValidatePlacement() = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidPlacement) {
+				t.Errorf("ValidatePlacement() = %v, want ErrInvalidPlacement", err)
+			}
+		})
+	}
+}
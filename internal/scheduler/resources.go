@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// ErrInvalidResources is returned for tasks requesting negative amounts
+var ErrInvalidResources = errors.New("invalid resource requests")
+
+// ErrUnschedulable is returned by SelectWorker when no registered worker
+// matches the task's placement, or none it allows could hold its requests
+// even while idle
+var ErrUnschedulable = errors.New("task does not fit any worker")
+
+// Resources is an amount of CPU, memory and named resources. It is used for
+// worker capacity, allocations and task requests.
+type Resources struct {
+	CPUMillis   int64            // 1000 = one core
+	MemoryBytes int64            // bytes
+	Named       map[string]int64 // e.g. "gpu": 2
+}
+
+// ResourcesFromProto converts the wire format; nil is the zero amount
+func ResourcesFromProto(r *pb.Resources) Resources {
+	if r == nil {
+		return Resources{}
+	}
+	out := Resources{CPUMillis: r.CpuMillis, MemoryBytes: r.MemoryBytes}
+	if len(r.Named) > 0 {
+		out.Named = make(map[string]int64, len(r.Named))
+		for k, v := range r.Named {
+			out.Named[k] = v
+		}
+	}
+	return out
+}
+
+// ValidateResources checks that a task does not request negative amounts
+func ValidateResources(r *pb.Resources) error {
+	if r == nil {
+		return nil
+	}
+	if r.CpuMillis < 0 || r.MemoryBytes < 0 {
+		return fmt.Errorf("%w: cpu and memory must not be negative", ErrInvalidResources)
+	}
+	for k, v := range r.Named {
+		if k == "" || v < 0 {
+			return fmt.Errorf("%w: named resource %q=%d", ErrInvalidResources, k, v)
+		}
+	}
+	return nil
+}
+
+// IsZero reports whether r holds no resources at all
+func (r Resources) IsZero() bool {
+	if r.CPUMillis != 0 || r.MemoryBytes != 0 {
+		return false
+	}
+	for _, v := range r.Named {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Add returns r + o
+func (r Resources) Add(o Resources) Resources {
+	return r.combine(o, 1)
+}
+
+// Sub returns r - o
+func (r Resources) Sub(o Resources) Resources {
+	return r.combine(o, -1)
+}
+
+func (r Resources) combine(o Resources, sign int64) Resources {
+	out := Resources{
+		CPUMillis:   r.CPUMillis + sign*o.CPUMillis,
+		MemoryBytes: r.MemoryBytes + sign*o.MemoryBytes,
+	}
+	if len(r.Named)+len(o.Named) > 0 {
+		out.Named = make(map[string]int64, len(r.Named)+len(o.Named))
+		for k, v := range r.Named {
+			out.Named[k] = v
+		}
+		for k, v := range o.Named {
+			out.Named[k] += sign * v
+			if out.Named[k] == 0 {
+				delete(out.Named, k)
+			}
+		}
+	}
+	return out
+}
+
+// Fits reports whether request fits into the free amount of a worker with
+// the given capacity. CPU and memory are only enforced when the worker
+// advertised them; named resources must always be advertised.
+func Fits(request, capacity, allocated Resources) bool {
+	if capacity.CPUMillis > 0 && allocated.CPUMillis+request.CPUMillis > capacity.CPUMillis {
+		return false
+	}
+	if capacity.MemoryBytes > 0 && allocated.MemoryBytes+request.MemoryBytes > capacity.MemoryBytes {
+		return false
+	}
+	for k, v := range request.Named {
+		if v > 0 && allocated.Named[k]+v > capacity.Named[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// Utilization is the mean share of capacity in use across the dimensions the
+// worker advertised, counting extra as already allocated. ok is false if the
+// worker advertised no resources.
+func Utilization(capacity, allocated, extra Resources) (u float64, ok bool) {
+	var sum float64
+	var n int
+	share := func(c, a int64) {
+		if c > 0 {
+			sum += float64(a) / float64(c)
+			n++
+		}
+	}
+	share(capacity.CPUMillis, allocated.CPUMillis+extra.CPUMillis)
+	share(capacity.MemoryBytes, allocated.MemoryBytes+extra.MemoryBytes)
+	for k, c := range capacity.Named {
+		share(c, allocated.Named[k]+extra.Named[k])
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
+
+// String formats r like "cpu=1500m mem=2147483648 gpu=1"
+func (r Resources) String() string {
+	parts := []string{fmt.Sprintf("cpu=%dm", r.CPUMillis), fmt.Sprintf("mem=%d", r.MemoryBytes)}
+	names := make([]string, 0, len(r.Named))
+	for k := range r.Named {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		parts = append(parts, fmt.Sprintf("%s=%d", k, r.Named[k]))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+func TestFits(t *testing.T) {
+	capacity := Resources{CPUMillis: 4000, MemoryBytes: 8 << 30, Named: map[string]int64{"gpu": 2}}
+
+	tests := []struct {
+		name      string
+		request   Resources
+		capacity  Resources
+		allocated Resources
+		want      bool
+	}{
+		{"nothing requested", Resources{}, capacity, Resources{}, true},
+		{"fits exactly", Resources{CPUMillis: 4000, MemoryBytes: 8 << 30}, capacity, Resources{}, true},
+		{"cpu exhausted", Resources{CPUMillis: 1000}, capacity, Resources{CPUMillis: 3500}, false},
+		{"memory exhausted", Resources{MemoryBytes: 1 << 30}, capacity, Resources{MemoryBytes: 8 << 30}, false},
+		{"named fits", Resources{Named: map[string]int64{"gpu": 1}}, capacity, Resources{Named: map[string]int64{"gpu": 1}}, true},
+		{"named exhausted", Resources{Named: map[string]int64{"gpu": 2}}, capacity, Resources{Named: map[string]int64{"gpu": 1}}, false},
+		{"named not advertised", Resources{Named: map[string]int64{"fpga": 1}}, capacity, Resources{}, false},
+		{"zero named request", Resources{Named: map[string]int64{"fpga": 0}}, capacity, Resources{}, true},
+		{"cpu not advertised", Resources{CPUMillis: 1 << 20}, Resources{}, Resources{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fits(tt.request, tt.capacity, tt.allocated); got != tt.want {
+				t.Errorf("Fits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUtilization(t *testing.T) {
+	tests := []struct {
+		name      string
+		capacity  Resources
+		allocated Resources
+		extra     Resources
+		want      float64
+		ok        bool
+	}{
+		{"nothing advertised", Resources{}, Resources{}, Resources{CPUMillis: 100}, 0, false},
+		{"cpu only", Resources{CPUMillis: 1000}, Resources{CPUMillis: 250}, Resources{CPUMillis: 250}, 0.5, true},
+		{"mean of dimensions", Resources{CPUMillis: 1000, MemoryBytes: 100}, Resources{CPUMillis: 1000}, Resources{}, 0.5, true},
+		{"named counts", Resources{Named: map[string]int64{"gpu": 4}}, Resources{Named: map[string]int64{"gpu": 1}}, Resources{Named: map[string]int64{"gpu": 1}}, 0.5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Utilization(tt.capacity, tt.allocated, tt.extra)
+			if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Utilization() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestResourcesAddSub(t *testing.T) {
+	a := Resources{CPUMillis: 1000, MemoryBytes: 10, Named: map[string]int64{"gpu": 1}}
+	b := Resources{CPUMillis: 500, Named: map[string]int64{"gpu": 1, "fpga": 2}}
+
+	sum := a.Add(b)
+	if sum.CPUMillis != 1500 || sum.MemoryBytes != 10 || sum.Named["gpu"] != 2 || sum.Named["fpga"] != 2 {
+		t.Fatalf("Add() = %v", sum)
+	}
+	if back := sum.Sub(b); back.String() != a.String() {
+		t.Errorf("Sub() = %v, want %v", back, a)
+	}
+	if !a.Sub(a).IsZero() {
+		t.Errorf("a - a = %v, want zero", a.Sub(a))
+	}
+}
+
+func TestValidateResources(t *testing.T) {
+	tests := []struct {
+		name  string
+		r     *pb.Resources
+		valid bool
+	}{
+		{"nil", nil, true},
+		{"positive", &pb.Resources{CpuMillis: 100, MemoryBytes: 1, Named: map[string]int64{"gpu": 1}}, true},
+		{"negative cpu", &pb.Resources{CpuMillis: -1}, false},
+		{"negative named", &pb.Resources{Named: map[string]int64{"gpu": -1}}, false},
+		{"empty name", &pb.Resources{Named: map[string]int64{"": 1}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateResources(tt.r)
+			if tt.valid != (err == nil) || (err != nil && !errors.Is(err, ErrInvalidResources)) {
+				t.Errorf("ValidateResources() = %v, valid %v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// ErrInvalidRetryPolicy is returned for tasks whose retry policy is malformed
+var ErrInvalidRetryPolicy = errors.New("invalid retry policy")
+
+// ValidateRetryPolicy checks the ranges of a retry policy.
+// A nil policy is valid and means the Dispatcher's default policy.
+func ValidateRetryPolicy(p *pb.RetryPolicy) error {
+	if p == nil {
+		return nil
+	}
+	if p.MaxAttempts < 0 {
+		return fmt.Errorf("%w: max_attempts is negative", ErrInvalidRetryPolicy)
+	}
+	if p.BackoffBaseMillis < 0 || p.BackoffMaxMillis < 0 {
+		return fmt.Errorf("%w: backoff is negative", ErrInvalidRetryPolicy)
+	}
+	if p.BackoffMaxMillis > 0 && p.BackoffMaxMillis < p.BackoffBaseMillis {
+		return fmt.Errorf("%w: backoff_max_millis is below backoff_base_millis", ErrInvalidRetryPolicy)
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("%w: jitter must be between 0 and 1", ErrInvalidRetryPolicy)
+	}
+	for _, c := range p.RetryOn {
+		if c == pb.ErrorClass_ERROR_CLASS_UNSPECIFIED {
+			return fmt.Errorf("%w: retry_on contains %s", ErrInvalidRetryPolicy, c)
+		}
+	}
+	return nil
+}
+
+// retryVerdict decides whether a task whose failures-th failed attempt
+// failed with class may be retried; reason explains a refusal
+func retryVerdict(p *pb.RetryPolicy, failures int32, class pb.ErrorClass) (retry bool, reason string) {
+	switch {
+	case p == nil || p.MaxAttempts <= 1:
+		return false, "retry policy allows no retries"
+	case failures >= p.MaxAttempts:
+		return false, fmt.Sprintf("all %d attempts failed", p.MaxAttempts)
+	case len(p.RetryOn) == 0 && class == pb.ErrorClass_ERROR_CLASS_PERMANENT,
+		len(p.RetryOn) > 0 && !slices.Contains(p.RetryOn, class):
+		return false, fmt.Sprintf("%s is not retried", class)
+	}
+	return true, ""
+}
+
+// retryDelay is the backoff before the n-th retry (n >= 1): the base doubled
+// n-1 times, capped at the maximum, then shortened by up to jitter at random
+// so tasks that failed together do not come back together
+func retryDelay(p *pb.RetryPolicy, n int32) time.Duration {
+	base := time.Duration(p.BackoffBaseMillis) * time.Millisecond
+	if base <= 0 {
+		return 0
+	}
+	limit := time.Duration(p.BackoffMaxMillis) * time.Millisecond
+	delay := base
+	for i := int32(1); i < n && (limit <= 0 || delay < limit) && delay < math.MaxInt64/2; i++ {
+		delay *= 2
+	}
+	if limit > 0 && delay > limit {
+		delay = limit
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(p.Jitter * rand.Float64() * float64(delay))
+	}
+	return delay
+}
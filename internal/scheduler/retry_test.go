@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+func TestRetryVerdict(t *testing.T) {
+	var (
+		handler   = pb.ErrorClass_ERROR_CLASS_HANDLER
+		permanent = pb.ErrorClass_ERROR_CLASS_PERMANENT
+		timeout   = pb.ErrorClass_ERROR_CLASS_TIMEOUT
+	)
+	three := &pb.RetryPolicy{MaxAttempts: 3}
+	onlyTimeouts := &pb.RetryPolicy{MaxAttempts: 3, RetryOn: []pb.ErrorClass{timeout}}
+
+	tests := []struct {
+		name     string
+		policy   *pb.RetryPolicy
+		failures int32
+		class    pb.ErrorClass
+		want     bool
+	}{
+		{"no policy", nil, 1, handler, false},
+		{"single attempt", &pb.RetryPolicy{MaxAttempts: 1}, 1, handler, false},
+		{"first failure", three, 1, handler, true},
+		{"second failure", three, 2, handler, true},
+		{"budget spent", three, 3, handler, false},
+		{"permanent by default", three, 1, permanent, false},
+		{"timeout by default", three, 1, timeout, true},
+		{"listed class", onlyTimeouts, 1, timeout, true},
+		{"unlisted class", onlyTimeouts, 1, handler, false},
+		{"listed permanent", &pb.RetryPolicy{MaxAttempts: 3, RetryOn: []pb.ErrorClass{permanent}}, 1, permanent, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retry, reason := retryVerdict(tt.policy, tt.failures, tt.class)
+			if retry != tt.want {
+				t.Errorf("retryVerdict() = %v (%s), want %v", retry, reason, tt.want)
+			}
+			if !retry && reason == "" {
+				t.Error("refusal without a reason")
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	p := &pb.RetryPolicy{BackoffBaseMillis: 100, BackoffMaxMillis: 1000}
+
+	tests := []struct {
+		name   string
+		policy *pb.RetryPolicy
+		n      int32
+		want   time.Duration
+	}{
+		{"first retry", p, 1, 100 * time.Millisecond},
+		{"doubles", p, 2, 200 * time.Millisecond},
+		{"doubles again", p, 4, 800 * time.Millisecond},
+		{"capped", p, 5, time.Second},
+		{"stays capped", p, 1000, time.Second},
+		{"no base", &pb.RetryPolicy{BackoffMaxMillis: 1000}, 3, 0},
+		{"uncapped", &pb.RetryPolicy{BackoffBaseMillis: 1}, 11, 1024 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryDelay(tt.policy, tt.n); got != tt.want {
+				t.Errorf("retryDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryDelayOverflow(t *testing.T) {
+	if got := retryDelay(&pb.RetryPolicy{BackoffBaseMillis: 1}, 1000); got <= 0 {
+		t.Errorf("retryDelay() = %v, want a positive delay", got)
+	}
+}
+
+func TestRetryDelayJitter(t *testing.T) {
+	p := &pb.RetryPolicy{BackoffBaseMillis: 1000, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		if got := retryDelay(p, 1); got < 500*time.Millisecond || got > time.Second {
+			t.Fatalf("retryDelay() = %v, want between 500ms and 1s", got)
+		}
+	}
+}
+
+func TestValidateRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *pb.RetryPolicy
+		valid  bool
+	}{
+		{"nil", nil, true},
+		{"full", &pb.RetryPolicy{MaxAttempts: 3, BackoffBaseMillis: 100, BackoffMaxMillis: 1000, Jitter: 0.2}, true},
+		{"negative attempts", &pb.RetryPolicy{MaxAttempts: -1}, false},
+		{"negative backoff", &pb.RetryPolicy{BackoffBaseMillis: -1}, false},
+		{"max below base", &pb.RetryPolicy{BackoffBaseMillis: 100, BackoffMaxMillis: 50}, false},
+		{"jitter above 1", &pb.RetryPolicy{Jitter: 1.5}, false},
+		{"unspecified class", &pb.RetryPolicy{RetryOn: []pb.ErrorClass{pb.ErrorClass_ERROR_CLASS_UNSPECIFIED}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRetryPolicy(tt.policy)
+			if tt.valid != (err == nil) || (err != nil && !errors.Is(err, ErrInvalidRetryPolicy)) {
+				t.Errorf("ValidateRetryPolicy() = %v, valid %v", err, tt.valid)
+			}
+		})
+	}
+}
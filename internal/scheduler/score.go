@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"math"
+	"time"
+
+	"github.com/YilinZhang0101/SwiftScheduler/internal/ring"
+)
+
+// defaultOutcomeSamples is the number of recent task results the failure
+// rate is computed over
+const defaultOutcomeSamples = 50
+
+// ScoreConfig weighs the signals combined into a worker's health score.
+// Each signal is a penalty between 0 (good) and 1 (bad); the score is
+// 1 minus their weighted mean, so 1 is a perfect worker and 0 the worst.
+// A zero weight ignores the signal.
+type ScoreConfig struct {
+	LoadWeight      float64 // (active+queued) / MaxConcurrency
+	CPUWeight       float64 // host CPU usage
+	MemoryWeight    float64 // host or cgroup memory in use, whichever is higher
+	FailureWeight   float64 // share of failed tasks among recent results
+	RTTWeight       float64 // mean RTT, as RTT / (RTT + RTTReference)
+	FreshnessWeight float64 // silence relative to the Dead threshold of the health model
+
+	// RTTReference is the RTT that counts as a 0.5 penalty
+	RTTReference time.Duration
+}
+
+// DefaultScoreConfig weighs load and failures highest: they say most about
+// how the next task will fare
+func DefaultScoreConfig() ScoreConfig {
+	return ScoreConfig{
+		LoadWeight:      2,
+		CPUWeight:       1,
+		MemoryWeight:    1,
+		FailureWeight:   2,
+		RTTWeight:       0.5,
+		FreshnessWeight: 1,
+		RTTReference:    50 * time.Millisecond,
+	}
+}
+
+// SetScoreConfig replaces the health score weights
+func (sm *StateManager) SetScoreConfig(cfg ScoreConfig) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.score = cfg
+}
+
+// RecordOutcome counts a task result towards the worker's failure rate
+func (sm *StateManager) RecordOutcome(workerID string, succeeded bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if ws, ok := sm.workers[workerID]; ok {
+		ws.Outcomes.Add(succeeded)
+	}
+}
+
+// scoreOf computes a worker's health score from its snapshot. Signals the
+// worker has not reported yet (e.g. metrics off Linux, no RTT sample) are
+// left out instead of counting as perfect.
+// Callers must hold sm.mu.
+func (sm *StateManager) scoreOf(ws *WorkerStats, snap WorkerSnapshot, now time.Time) float64 {
+	cfg := sm.score
+	var penalty, weights float64
+	add := func(weight, p float64) {
+		if weight <= 0 {
+			return
+		}
+		penalty += weight * math.Max(0, math.Min(1, p))
+		weights += weight
+	}
+
+	if snap.MaxConcurrency > 0 {
+		add(cfg.LoadWeight, float64(snap.Load())/float64(snap.MaxConcurrency))
+	}
+	if m := snap.Metrics; m != nil {
+		add(cfg.CPUWeight, m.CpuUsage)
+		var mem float64
+		if m.MemTotalBytes > 0 {
+			mem = 1 - float64(m.MemAvailableBytes)/float64(m.MemTotalBytes)
+		}
+		if m.CgroupMemoryLimitBytes > 0 {
+			mem = math.Max(mem, float64(m.CgroupMemoryUsageBytes)/float64(m.CgroupMemoryLimitBytes))
+		}
+		add(cfg.MemoryWeight, mem)
+	}
+	if rate, ok := ws.Outcomes.FailureRate(); ok {
+		add(cfg.FailureWeight, rate)
+	}
+	if ws.RTT.Len() > 0 && cfg.RTTReference > 0 {
+		add(cfg.RTTWeight, float64(snap.RTT)/float64(snap.RTT+cfg.RTTReference))
+	}
+	if sm.health.Model == HealthModelPhi {
+		add(cfg.FreshnessWeight, snap.Phi/sm.health.PhiDead)
+	} else {
+		silence, _, deadAfter := sm.silenceOf(ws, now)
+		add(cfg.FreshnessWeight, float64(silence)/float64(deadAfter))
+	}
+
+	if weights == 0 {
+		return 1
+	}
+	return 1 - penalty/weights
+}
+
+// OutcomeRing remembers whether recent tasks succeeded.
+// It is not thread-safe; the StateManager guards it.
+type OutcomeRing struct {
+	failed   *ring.Ring[bool]
+	failures int // failed results currently in the ring
+}
+
+// NewOutcomeRing constructs an OutcomeRing holding up to size results
+func NewOutcomeRing(size int) *OutcomeRing {
+	return &OutcomeRing{failed: ring.New[bool](size)}
+}
+
+// Add records a result, forgetting the oldest one once the ring is full
+func (r *OutcomeRing) Add(succeeded bool) {
+	if old, evicted := r.failed.Add(!succeeded); evicted && old {
+		r.failures--
+	}
+	if !succeeded {
+		r.failures++
+	}
+}
+
+// FailureRate returns the share of failed results; ok is false without any
+func (r *OutcomeRing) FailureRate() (rate float64, ok bool) {
+	if r.failed.Len() == 0 {
+		return 0, false
+	}
+	return float64(r.failures) / float64(r.failed.Len()), true
+}
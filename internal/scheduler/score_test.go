@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+func TestHealthScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    ScoreConfig
+		update func(ws *WorkerStats)
+		want   float64
+	}{
+		{"idle", DefaultScoreConfig(), func(ws *WorkerStats) {}, 1},
+		// weights: load 2, freshness 1
+		{"half loaded", DefaultScoreConfig(), func(ws *WorkerStats) { ws.ActiveTaskCount = 2 }, 1 - 1.0/3},
+		{"full", DefaultScoreConfig(), func(ws *WorkerStats) { ws.ActiveTaskCount = 3; ws.QueuedTaskCount = 1 }, 1 - 2.0/3},
+		// weights: load 2, failures 2, freshness 1
+		{"half the results failed", DefaultScoreConfig(), func(ws *WorkerStats) {
+			ws.Outcomes.Add(true)
+			ws.Outcomes.Add(false)
+		}, 1 - 1.0/5},
+		// weights: load 2, cpu 1, memory 1, freshness 1
+		{"host saturated", DefaultScoreConfig(), func(ws *WorkerStats) {
+			ws.Metrics = &pb.NodeMetrics{CpuUsage: 1, MemTotalBytes: 100, MemAvailableBytes: 0}
+		}, 1 - 2.0/5},
+		{"cgroup limit counts", DefaultScoreConfig(), func(ws *WorkerStats) {
+			ws.Metrics = &pb.NodeMetrics{MemTotalBytes: 100, MemAvailableBytes: 100, CgroupMemoryLimitBytes: 10, CgroupMemoryUsageBytes: 10}
+		}, 1 - 1.0/5},
+		// weights: load 2, rtt 0.5, freshness 1; RTTReference is a 0.5 penalty
+		{"slow link", DefaultScoreConfig(), func(ws *WorkerStats) { ws.RTT.Add(50 * time.Millisecond) }, 1 - 0.25/3.5},
+		{"nothing weighed", ScoreConfig{}, func(ws *WorkerStats) { ws.ActiveTaskCount = 4 }, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewStateManager()
+			sm.SetScoreConfig(tt.cfg)
+			sm.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4}, "w1", newFakeStream(), nil)
+
+			sm.mu.Lock()
+			defer sm.mu.Unlock()
+			ws := sm.workers["w1"]
+			tt.update(ws)
+			// score at the last heartbeat, so freshness adds no penalty
+			if got := sm.snapshot(ws, ws.LastHeartbeat).Score; math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("score = %.4f, want %.4f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthScoreFreshness(t *testing.T) {
+	sm := NewStateManager()
+	sm.SetScoreConfig(ScoreConfig{FreshnessWeight: 1})
+	sm.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4}, "w1", newFakeStream(), nil)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	ws := sm.workers["w1"]
+	dead := sm.health.DeadAfter
+	for _, tt := range []struct {
+		silence time.Duration
+		want    float64
+	}{{0, 1}, {dead / 2, 0.5}, {dead, 0}, {2 * dead, 0}} {
+		if got := sm.snapshot(ws, ws.LastHeartbeat.Add(tt.silence)).Score; math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("score after %v of silence = %.4f, want %.4f", tt.silence, got, tt.want)
+		}
+	}
+}
+
+func TestHealthScoreFreshnessKeepalive(t *testing.T) {
+	sm := NewStateManager()
+	cfg := DefaultHealthConfig()
+	cfg.Model = HealthModelKeepalive
+	cfg.Deadline = func(workerID string) time.Duration { return 3 * time.Second }
+	sm.SetHealthConfig(cfg)
+	sm.SetScoreConfig(ScoreConfig{FreshnessWeight: 1})
+	sm.RegisterWorker(&pb.RegisterRequest{MaxConcurrency: 4}, "w1", newFakeStream(), nil)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	ws := sm.workers["w1"]
+	// Dead after two deadlines of silence, as in healthOf
+	for _, tt := range []struct {
+		name    string
+		silence time.Duration
+		pong    time.Duration // pong this long after the last heartbeat, 0 for none
+		want    float64
+	}{
+		{"just heard from", 0, 0, 1},
+		{"one deadline", 3 * time.Second, 0, 0.5},
+		{"two deadlines", 6 * time.Second, 0, 0},
+		{"recent pong", 6 * time.Second, 3 * time.Second, 0.5},
+	} {
+		ws.LastPong = time.Time{}
+		if tt.pong > 0 {
+			ws.LastPong = ws.LastHeartbeat.Add(tt.pong)
+		}
+		if got := sm.snapshot(ws, ws.LastHeartbeat.Add(tt.silence)).Score; math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: score = %.4f, want %.4f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOutcomeRing(t *testing.T) {
+	r := NewOutcomeRing(4)
+	if _, ok := r.FailureRate(); ok {
+		t.Error("FailureRate of an empty ring reported ok")
+	}
+	for _, succeeded := range []bool{false, false, true, true, true, true} {
+		r.Add(succeeded)
+	}
+	// the two failures were pushed out
+	if rate, ok := r.FailureRate(); !ok || rate != 0 {
+		t.Errorf("FailureRate() = %v, %v; want 0", rate, ok)
+	}
+	r.Add(false)
+	if rate, _ := r.FailureRate(); rate != 0.25 {
+		t.Errorf("FailureRate() = %v, want 0.25", rate)
+	}
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// Acknowledger is implemented by task sources that must learn the fate of the
+// tasks they hand to the Dispatcher, e.g. a message broker with manual acks
+type Acknowledger interface {
+	// Ack is called once the task reached a terminal state
+	Ack(taskID string) error
+	// Nack hands the task back to the source; requeue asks the source to
+	// deliver it again
+	Nack(taskID string, requeue bool) error
+}
+
+// TaskSource is where the Master gets tasks from (a broker, a file, the
+// TaskService API, ...). The Dispatcher pulls with Next and reports every
+// task's fate through Ack/Nack, so it does not depend on any particular broker.
+type TaskSource interface {
+	// Next blocks until a task is available. An error ends consumption.
+	Next(ctx context.Context) (*pb.TaskAssignment, error)
+	Acknowledger
+}
+
+// SubmitObserver is optionally implemented by a TaskSource that needs to know
+// whether the Dispatcher accepted each task, e.g. to answer a client
+type SubmitObserver interface {
+	Submitted(taskID string, err error)
+}
+
+// Consume pulls tasks from src into the dispatch queue until Next fails or
+// ctx is cancelled. A task ID the Dispatcher already knows is a redelivery
+// (e.g. after the source reconnected, or the Master restarted and adopted the
+// task) as long as the task is unfinished: the new delivery becomes the one
+// acked at the end. Finished tasks and repeated idempotency keys are acked as
+// duplicates; other rejected tasks are nacked without requeue.
+func (d *Dispatcher) Consume(ctx context.Context, src TaskSource) error {
+	for {
+		task, err := src.Next(ctx)
+		if err != nil {
+			return err
+		}
+
+		err = d.SubmitFrom(task, src)
+		if obs, ok := src.(SubmitObserver); ok {
+			obs.Submitted(task.TaskId, err)
+		}
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrTaskExists) && d.reattach(task.TaskId, src):
+			// acking it now would lose the task if the Master crashed before
+			// it finished
+			log.Printf("[Dispatcher] Task %s redelivered by its source", task.TaskId)
+		case errors.Is(err, ErrTaskExists), errors.Is(err, ErrDuplicateTask):
+			log.Printf("[Dispatcher] Duplicate task %s from source acked", task.TaskId)
+			src.Ack(task.TaskId)
+		default:
+			log.Printf("[Dispatcher] Rejected task %s from source: %v", task.TaskId, err)
+			src.Nack(task.TaskId, false)
+		}
+	}
+}
@@ -1,23 +1,62 @@
 package scheduler
 
 import (
+	"fmt"
 	"log"
+	"sort"
 	"sync" // sync for mutexes
 	"errors"
+	"time"
 
+	"github.com/YilinZhang0101/SwiftScheduler/internal/ring"
 	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
 )
 
+// defaultRTTSamples is the capacity of each worker's RTT ring buffer
+const defaultRTTSamples = 64
+
 // WorkerStats stores the Master's knowledge about a Worker.
 // This is the data foundation for our "global view".
 type WorkerStats struct {
 	ID             string
 	Hostname       string
 	MaxConcurrency int32
+	Labels         map[string]string // from registration; never modified afterwards
+	// Capacity is what the worker advertised; Allocated is the sum of the
+	// requests of its in-flight tasks
+	Capacity  Resources
+	Allocated Resources
 	// --- Core load metric for Phase 1 ---
 	ActiveTaskCount int32
-	// TODO: In Phase 2, expand to a richer health score
+	QueuedTaskCount int32 // assigned but not yet started on the worker
+	// Metrics, Latency and TaskLatency come with every StatusUpdate; nil
+	// until the worker reports them (Metrics stays nil off Linux)
+	Metrics     *pb.NodeMetrics
+	Latency     *pb.LatencyStats            // all task names together
+	TaskLatency map[string]*pb.LatencyStats // key is task_name
+	// InFlight holds the tasks dispatched to this worker that have not reported
+	// a result yet; they are requeued if the worker goes away
+	InFlight map[string]*pb.TaskAssignment // key is task_id
+	// RTT samples from the master's Ping/Pong probes, newest last
+	RTT *ring.Ring[time.Duration]
+	// --- Liveness ---
+	LastHeartbeat time.Time   // when the last StatusUpdate (or registration) arrived
+	LastPong      time.Time   // when the last Pong arrived, zero if none
+	Health        HealthState // refreshed by the reaper
+	// Draining workers finish their in-flight tasks but get no new ones
+	Draining bool
+	// Phi is the continuous suspicion level from PhiDetector, refreshed by the
+	// reaper; 0-1 is normal, it grows quickly once heartbeats stop
+	Phi         float64
+	PhiDetector *PhiAccrual
+	// Outcomes feeds the failure rate of the health score (see ScoreConfig)
+	Outcomes *OutcomeRing
 	Stream pb.SchedulerService_ConnectServer
+	// sendMu serializes Send calls on Stream; gRPC streams are not safe for
+	// concurrent senders (Connect handler + Dispatcher)
+	sendMu sync.Mutex
+	// closeStream makes the Connect handler return, closing the stream
+	closeStream func()
 }
 
 // StateManager manages all workers' state.
@@ -26,39 +65,168 @@ type StateManager struct {
 	// RWMutex allows concurrent readers and exclusive writers
 	mu      sync.RWMutex
 	workers map[string]*WorkerStats // key is worker_id
+	health  HealthConfig
+	score   ScoreConfig // weights of the composite health score
+	// strategy picks a worker among the available ones
+	strategy Strategy
+
+	// onWorkerLost receives the in-flight tasks of an unregistered or evicted
+	// worker; it is called without holding mu
+	onWorkerLost func(workerID string, tasks []*pb.TaskAssignment)
 }
 
 // NewStateManager constructs a StateManager
 func NewStateManager() *StateManager {
 	return &StateManager{
-		workers: make(map[string]*WorkerStats),
+		workers:  make(map[string]*WorkerStats),
+		health:   DefaultHealthConfig(),
+		score:    DefaultScoreConfig(),
+		strategy: LeastLoad{},
 	}
 }
 
-// RegisterWorker is called when a worker connects
-func (sm *StateManager) RegisterWorker(req *pb.RegisterRequest, workerID string, stream pb.SchedulerService_ConnectServer) {
+// RegisterWorker is called when a worker connects.
+// closeStream must make the worker's Connect handler return; the reaper uses
+// it to evict Dead workers.
+func (sm *StateManager) RegisterWorker(req *pb.RegisterRequest, workerID string, stream pb.SchedulerService_ConnectServer, closeStream func()) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
+	now := time.Now()
 	stats := &WorkerStats{
 		ID:              workerID,
 		Hostname:        req.Hostname,
 		MaxConcurrency:  req.MaxConcurrency,
+		Labels:          req.Labels,
+		Capacity:        ResourcesFromProto(req.Capacity),
 		ActiveTaskCount: 0, // newly registered worker starts with 0 active tasks
+		InFlight:        make(map[string]*pb.TaskAssignment),
+		RTT:             ring.New[time.Duration](defaultRTTSamples),
+		LastHeartbeat:   now,
+		PhiDetector:     NewPhiAccrual(sm.health.Phi, now),
+		Outcomes:        NewOutcomeRing(defaultOutcomeSamples),
+		Health:          Healthy,
+		Draining:        req.Draining,
 		Stream:          stream, // store the data stream
+		closeStream:     closeStream,
+	}
+	// a worker re-registering under the same ID replaces its old session and
+	// keeps its in-flight tasks
+	if old, ok := sm.workers[workerID]; ok {
+		stats.InFlight = old.InFlight
+		stats.Outcomes = old.Outcomes
+		stats.Draining = stats.Draining || old.Draining
+		for _, t := range stats.InFlight {
+			stats.Allocated = stats.Allocated.Add(ResourcesFromProto(t.Requests))
+		}
+		if old.closeStream != nil {
+			old.closeStream()
+		}
 	}
 	sm.workers[workerID] = stats
 
-	log.Printf("[StateManager] Worker %s registered. Total workers: %d", workerID, len(sm.workers))
+	log.Printf("[StateManager] Worker %s registered (%s). Total workers: %d", workerID, stats.Capacity, len(sm.workers))
+}
+
+// Registered reports whether a worker is registered under workerID
+func (sm *StateManager) Registered(workerID string) bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	_, ok := sm.workers[workerID]
+	return ok
+}
+
+// UnregisterWorker is called when a worker disconnects and reports whether
+// the registration was removed. Only the registration owning stream is
+// removed, so a stale Connect handler (e.g. one whose worker was already
+// evicted) cannot remove a newer one.
+func (sm *StateManager) UnregisterWorker(workerID string, stream pb.SchedulerService_ConnectServer) bool {
+	sm.mu.Lock()
+	ws, ok := sm.workers[workerID]
+	if !ok || ws.Stream != stream {
+		sm.mu.Unlock()
+		return false
+	}
+	delete(sm.workers, workerID)
+	remaining := len(sm.workers)
+	sm.mu.Unlock()
+
+	log.Printf("[StateManager] Worker %s unregistered. Total workers: %d", workerID, remaining)
+	sm.workerLost(ws)
+	return true
 }
 
-// UnregisterWorker is called when a worker disconnects
-func (sm *StateManager) UnregisterWorker(workerID string) {
+// DeregisterWorker removes a worker that shut down cleanly and returns the
+// tasks it did not finish; false if stream no longer owns the registration.
+// Unlike UnregisterWorker the worker-lost handler is not called: the worker
+// is gone for good, so the caller requeues the tasks right away.
+func (sm *StateManager) DeregisterWorker(workerID string, stream pb.SchedulerService_ConnectServer) ([]*pb.TaskAssignment, bool) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
+	ws, ok := sm.workers[workerID]
+	if !ok || ws.Stream != stream {
+		return nil, false
+	}
 	delete(sm.workers, workerID)
-	log.Printf("[StateManager] Worker %s unregistered. Total workers: %d", workerID, len(sm.workers))
+
+	tasks := make([]*pb.TaskAssignment, 0, len(ws.InFlight))
+	for _, t := range ws.InFlight {
+		tasks = append(tasks, t)
+	}
+	ws.InFlight = make(map[string]*pb.TaskAssignment)
+
+	log.Printf("[StateManager] Worker %s deregistered with %d unfinished tasks. Total workers: %d", workerID, len(tasks), len(sm.workers))
+	return tasks, true
+}
+
+// SetDraining marks a worker as draining so SelectWorker skips it.
+// It returns the number of tasks the worker holds.
+func (sm *StateManager) SetDraining(workerID string) (int, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	ws, ok := sm.workers[workerID]
+	if !ok {
+		return 0, fmt.Errorf("worker %s is not registered", workerID)
+	}
+	if !ws.Draining {
+		ws.Draining = true
+		log.Printf("[StateManager] Worker %s is draining (%d tasks in flight)", workerID, len(ws.InFlight))
+	}
+	return len(ws.InFlight), nil
+}
+
+// SetWorkerLostHandler installs the callback that receives the in-flight
+// tasks of workers that disconnect or are evicted (normally Dispatcher.Requeue)
+func (sm *StateManager) SetWorkerLostHandler(fn func(workerID string, tasks []*pb.TaskAssignment)) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.onWorkerLost = fn
+}
+
+// workerLost hands a removed worker's in-flight tasks to the handler.
+// The worker must already be deleted from sm.workers, so nobody else touches
+// its InFlight map. Callers must not hold sm.mu.
+func (sm *StateManager) workerLost(ws *WorkerStats) {
+	if len(ws.InFlight) == 0 {
+		return
+	}
+	tasks := make([]*pb.TaskAssignment, 0, len(ws.InFlight))
+	for _, t := range ws.InFlight {
+		tasks = append(tasks, t)
+	}
+	ws.InFlight = make(map[string]*pb.TaskAssignment)
+
+	sm.mu.RLock()
+	fn := sm.onWorkerLost
+	sm.mu.RUnlock()
+
+	if fn == nil {
+		log.Printf("[StateManager] Worker %s lost %d in-flight tasks (no handler)", ws.ID, len(tasks))
+		return
+	}
+	fn(ws.ID, tasks)
 }
 
 // UpdateWorkerStatus updates a worker's status based on a StatusUpdate
@@ -68,38 +236,231 @@ func (sm *StateManager) UpdateWorkerStatus(workerID string, update *pb.StatusUpd
 
 	if ws, ok := sm.workers[workerID]; ok {
 		ws.ActiveTaskCount = update.ActiveTaskCount
+		ws.QueuedTaskCount = update.QueuedTaskCount
+		ws.Metrics = update.Metrics
+		ws.Latency = update.Latency
+		ws.TaskLatency = update.TaskLatency
+		now := time.Now()
+		ws.LastHeartbeat = now
+		ws.PhiDetector.Heartbeat(now, update.Periodic)
 		return
 	}
 	log.Printf("[StateManager] Received status for unknown worker: %s", workerID)
 }
 
-// SelectWorker finds the best available Worker
-// Strategy: Least Load (select the worker with the least active tasks and not at full capacity)
-func (sm *StateManager) SelectWorker() (string, pb.SchedulerService_ConnectServer, error) {
+// RecordRTT stores an RTT sample measured by the Ping/Pong prober
+func (sm *StateManager) RecordRTT(workerID string, rtt time.Duration) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if ws, ok := sm.workers[workerID]; ok {
+		ws.RTT.Add(rtt)
+		ws.LastPong = time.Now()
+	}
+}
+
+// RTTStats returns the mean and latest RTT of a worker and the number of
+// samples they are based on
+func (sm *StateManager) RTTStats(workerID string) (mean, last time.Duration, n int) {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
-	var bestWorker *WorkerStats
-	minLoad := int32(1<<31 - 1) // Max Int32
+	ws, ok := sm.workers[workerID]
+	if !ok {
+		return 0, 0, 0
+	}
+	last, _ = ws.RTT.Last()
+	return ring.Mean(ws.RTT), last, ws.RTT.Len()
+}
+
+// AddInFlight records that a task was dispatched to a worker.
+// It returns false if the worker is not registered.
+func (sm *StateManager) AddInFlight(workerID string, task *pb.TaskAssignment) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	ws, ok := sm.workers[workerID]
+	if !ok {
+		return false
+	}
+	ws.InFlight[task.TaskId] = task
+	ws.Allocated = ws.Allocated.Add(ResourcesFromProto(task.Requests))
+	return true
+}
+
+// RemoveInFlight forgets a dispatched task, e.g. when its result arrived, and
+// returns its assignment. It returns false if the worker did not hold the task.
+func (sm *StateManager) RemoveInFlight(workerID, taskID string) (*pb.TaskAssignment, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	ws, ok := sm.workers[workerID]
+	if !ok {
+		return nil, false
+	}
+	task, ok := ws.InFlight[taskID]
+	if !ok {
+		return nil, false
+	}
+	delete(ws.InFlight, taskID)
+	ws.Allocated = ws.Allocated.Sub(ResourcesFromProto(task.Requests))
+	return task, true
+}
+
+// IncrementActiveTasks optimistically bumps a worker's ActiveTaskCount after a
+// task has been pushed to it. The next StatusUpdate overwrites the value with
+// the worker's own count, but until then back-to-back dispatches see the
+// new load instead of all landing on the same worker.
+func (sm *StateManager) IncrementActiveTasks(workerID string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if ws, ok := sm.workers[workerID]; ok {
+		ws.ActiveTaskCount++
+	}
+}
+
+// DecrementActiveTasks is the counterpart of IncrementActiveTasks, called when
+// a worker reports a finished task
+func (sm *StateManager) DecrementActiveTasks(workerID string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if ws, ok := sm.workers[workerID]; ok && ws.ActiveTaskCount > 0 {
+		ws.ActiveTaskCount--
+	}
+}
+
+// SendToWorker sends a message over the worker's stored stream
+func (sm *StateManager) SendToWorker(workerID string, msg *pb.MasterMessage) error {
+	sm.mu.RLock()
+	ws, ok := sm.workers[workerID]
+	sm.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("worker %s is not registered", workerID)
+	}
+
+	// hold only the per-worker lock while sending so a slow stream does not
+	// block the whole StateManager
+	ws.sendMu.Lock()
+	defer ws.sendMu.Unlock()
+	return ws.Stream.Send(msg)
+}
 
+// SetStrategy replaces the placement strategy used by SelectWorker
+func (sm *StateManager) SetStrategy(strategy Strategy) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.strategy = strategy
+}
+
+// SelectWorker finds the best available Worker for a task.
+// Only Healthy, non-draining workers below MaxConcurrency whose labels satisfy the task's
+// placement and whose free resources cover its requests are considered; the configured Strategy picks among them
+// (LeastLoad by default).
+func (sm *StateManager) SelectWorker(task TaskSpec) (string, pb.SchedulerService_ConnectServer, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	now := time.Now()
+	candidates := make([]WorkerSnapshot, 0, len(sm.workers))
+	placed := 0 // workers allowed by the placement, before health and capacity
+	large := 0  // of those, workers whose capacity could hold the requests
+	sized := 0  // of those, workers with room for the requests
 	for _, worker := range sm.workers {
-		// 1. check if there is capacity
-		if worker.ActiveTaskCount >= worker.MaxConcurrency {
+		// 0. the task may be pinned to a pool of workers
+		if !MatchesPlacement(worker.Labels, task.Placement) {
+			continue
+		}
+		placed++
+
+		// 1. the worker must have room for the task's resource requests
+		if Fits(task.Requests, worker.Capacity, Resources{}) {
+			large++
+		}
+		if !Fits(task.Requests, worker.Capacity, worker.Allocated) {
 			continue
 		}
+		sized++
 
-		// 2. find the worker with the least load
-		if worker.ActiveTaskCount < minLoad {
-			minLoad = worker.ActiveTaskCount
-			bestWorker = worker
+		// 2. only Healthy workers receive tasks; a frozen worker stops sending
+		// heartbeats and must not look like the least loaded one. Draining
+		// workers are on their way out.
+		if sm.healthOf(worker, now) != Healthy || worker.Draining {
+			continue
 		}
+
+		// 3. check if there is a free slot; queued tasks will occupy a slot as
+		// soon as one frees up
+		if worker.ActiveTaskCount+worker.QueuedTaskCount >= worker.MaxConcurrency {
+			continue
+		}
+
+		// 4. rising phi makes a worker look busier so it gets less traffic
+		// before it is suspected
+		candidates = append(candidates, sm.snapshot(worker, now))
 	}
 
-	if bestWorker == nil {
+	if len(candidates) == 0 {
+		if placed == 0 && len(sm.workers) > 0 {
+			return "", nil, fmt.Errorf("%w: no registered worker matches the task's placement", ErrUnschedulable)
+		}
+		if large == 0 && placed > 0 {
+			return "", nil, fmt.Errorf("%w: no worker matching its placement has the capacity for %s", ErrUnschedulable, task.Requests)
+		}
+		if sized == 0 && placed > 0 {
+			return "", nil, errors.New("no worker has enough free resources for the task")
+		}
 		return "", nil, errors.New("no available workers found")
 	}
 
-	return bestWorker.ID, bestWorker.Stream, nil
+	// 5. map iteration order is random; sort so strategies are deterministic
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].ID < candidates[j].ID })
+	chosen := sm.workers[candidates[sm.strategy.Select(task, candidates)].ID]
+	return chosen.ID, chosen.Stream, nil
+}
+
+// Workers returns a snapshot of every registered worker, sorted by ID, e.g.
+// for display
+func (sm *StateManager) Workers() []WorkerSnapshot {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	now := time.Now()
+	out := make([]WorkerSnapshot, 0, len(sm.workers))
+	for _, ws := range sm.workers {
+		out = append(out, sm.snapshot(ws, now))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// snapshot copies what strategies and callers may see of a worker.
+// Callers must hold sm.mu.
+func (sm *StateManager) snapshot(ws *WorkerStats, now time.Time) WorkerSnapshot {
+	snap := WorkerSnapshot{
+		ID:              ws.ID,
+		Hostname:        ws.Hostname,
+		MaxConcurrency:  ws.MaxConcurrency,
+		Labels:          ws.Labels,
+		Capacity:        ws.Capacity,
+		Allocated:       ws.Allocated,
+		ActiveTaskCount: ws.ActiveTaskCount,
+		QueuedTaskCount: ws.QueuedTaskCount,
+		Health:          sm.healthOf(ws, now),
+		Draining:        ws.Draining,
+		Phi:             ws.PhiDetector.Phi(now),
+		RTT:             ring.Mean(ws.RTT),
+		Metrics:         ws.Metrics,
+		Latency:         ws.Latency,
+		TaskLatency:     ws.TaskLatency,
+	}
+	if sm.health.PhiWeight > 0 {
+		snap.Penalty = sm.health.PhiWeight * snap.Phi
+	}
+	snap.FailureRate, _ = ws.Outcomes.FailureRate()
+	snap.Score = sm.scoreOf(ws, snap, now)
+	return snap
 }
 
 // GetGlobalLoad returns (sumActive, sumCapacity)
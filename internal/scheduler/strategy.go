@@ -0,0 +1,269 @@
+package scheduler
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// WorkerSnapshot is a read-only copy of the WorkerStats a Strategy needs.
+// Strategies run while the StateManager holds its lock, so they get copies
+// instead of the live records.
+type WorkerSnapshot struct {
+	ID              string
+	Hostname        string
+	MaxConcurrency  int32
+	Labels          map[string]string // shared with WorkerStats, do not modify
+	Capacity        Resources
+	Allocated       Resources
+	ActiveTaskCount int32
+	QueuedTaskCount int32
+	Health          HealthState
+	Draining        bool
+	Phi             float64
+	// Penalty is extra load charged for a rising suspicion level
+	// (HealthConfig.PhiWeight * Phi); load-based strategies add it to Load
+	Penalty float64
+	// RTT is the mean Ping/Pong round trip
+	RTT time.Duration
+	// Metrics and latencies from the last StatusUpdate, shared with
+	// WorkerStats; do not modify. nil until reported.
+	Metrics     *pb.NodeMetrics
+	Latency     *pb.LatencyStats
+	TaskLatency map[string]*pb.LatencyStats
+	// FailureRate is the share of failed tasks among the recent results
+	FailureRate float64
+	// Score is the composite health score, 0 (worst) to 1 (best); see
+	// ScoreConfig
+	Score float64
+}
+
+// Load is the number of slots the worker has promised: running plus queued
+func (w WorkerSnapshot) Load() int32 {
+	return w.ActiveTaskCount + w.QueuedTaskCount
+}
+
+// Free is the number of slots left
+func (w WorkerSnapshot) Free() int32 {
+	return w.MaxConcurrency - w.Load()
+}
+
+// TaskSpec describes the task being placed
+type TaskSpec struct {
+	ID        string
+	Name      string
+	Placement *pb.Placement // nil when the task can run anywhere
+	Requests  Resources     // reserved on the chosen worker
+}
+
+// Strategy picks a worker for a task. candidates are Healthy workers with at
+// least one free slot that satisfy the task's placement and have room for its
+// requests, sorted by ID, and never empty. Select returns the index
+// of the chosen candidate. Implementations must be safe for concurrent use.
+type Strategy interface {
+	Name() string
+	Select(task TaskSpec, candidates []WorkerSnapshot) int
+}
+
+// Strategy names accepted by NewStrategy
+const (
+	StrategyLeastLoad      = "least-load"
+	StrategyRoundRobin     = "round-robin"
+	StrategyWeightedRandom = "weighted-random"
+	StrategyLeastLoadRatio = "least-load-ratio"
+	StrategyPowerOfTwo     = "power-of-two"
+	StrategyBinPack        = "bin-pack"
+	StrategySpread         = "spread"
+	StrategyHealthScore    = "health-score"
+)
+
+// NewStrategy returns the strategy registered under name
+func NewStrategy(name string) (Strategy, error) {
+	switch name {
+	case StrategyLeastLoad:
+		return LeastLoad{}, nil
+	case StrategyRoundRobin:
+		return &RoundRobin{}, nil
+	case StrategyWeightedRandom:
+		return WeightedRandom{}, nil
+	case StrategyLeastLoadRatio:
+		return LeastLoadRatio{}, nil
+	case StrategyPowerOfTwo:
+		return PowerOfTwo{}, nil
+	case StrategyBinPack:
+		return BinPack{}, nil
+	case StrategySpread:
+		return Spread{}, nil
+	case StrategyHealthScore:
+		return HealthScore{}, nil
+	default:
+		return nil, fmt.Errorf("unknown scheduling strategy %q", name)
+	}
+}
+
+// StrategyNames lists the names NewStrategy accepts
+func StrategyNames() []string {
+	names := []string{StrategyLeastLoad, StrategyRoundRobin, StrategyWeightedRandom, StrategyLeastLoadRatio, StrategyPowerOfTwo, StrategyBinPack, StrategySpread, StrategyHealthScore}
+	sort.Strings(names)
+	return names
+}
+
+// LeastLoad picks the worker with the fewest active+queued tasks.
+// Ties go to the lowest worker ID.
+type LeastLoad struct{}
+
+func (LeastLoad) Name() string { return StrategyLeastLoad }
+
+func (LeastLoad) Select(task TaskSpec, candidates []WorkerSnapshot) int {
+	return argmin(candidates, func(w WorkerSnapshot) float64 {
+		return float64(w.Load()) + w.Penalty
+	})
+}
+
+// LeastLoadRatio picks the worker with the lowest load relative to its
+// MaxConcurrency, so big workers take proportionally more tasks.
+// Ties go to the lowest worker ID.
+type LeastLoadRatio struct{}
+
+func (LeastLoadRatio) Name() string { return StrategyLeastLoadRatio }
+
+func (LeastLoadRatio) Select(task TaskSpec, candidates []WorkerSnapshot) int {
+	return argmin(candidates, loadRatio)
+}
+
+// RoundRobin hands tasks to the workers in ID order, one after another.
+// The cursor is the last chosen ID, so joining or leaving workers do not
+// reset the rotation.
+type RoundRobin struct {
+	mu   sync.Mutex
+	last string
+}
+
+func (*RoundRobin) Name() string { return StrategyRoundRobin }
+
+func (r *RoundRobin) Select(task TaskSpec, candidates []WorkerSnapshot) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// candidates are sorted by ID: take the first one after the cursor
+	i := sort.Search(len(candidates), func(i int) bool { return candidates[i].ID > r.last })
+	if i == len(candidates) {
+		i = 0
+	}
+	r.last = candidates[i].ID
+	return i
+}
+
+// WeightedRandom picks a worker at random with probability proportional to
+// its free slots, discounted by its phi penalty
+type WeightedRandom struct{}
+
+func (WeightedRandom) Name() string { return StrategyWeightedRandom }
+
+func (WeightedRandom) Select(task TaskSpec, candidates []WorkerSnapshot) int {
+	weights := make([]float64, len(candidates))
+	var total float64
+	for i, w := range candidates {
+		weights[i] = float64(w.Free()) / (1 + w.Penalty)
+		total += weights[i]
+	}
+
+	r := rand.Float64() * total
+	for i, weight := range weights {
+		if r < weight {
+			return i
+		}
+		r -= weight
+	}
+	return len(candidates) - 1
+}
+
+// PowerOfTwo samples two distinct workers at random and keeps the one with
+// the lower load ratio. It balances almost as well as LeastLoadRatio but does
+// not send every burst to the same momentarily idle worker.
+type PowerOfTwo struct{}
+
+func (PowerOfTwo) Name() string { return StrategyPowerOfTwo }
+
+func (PowerOfTwo) Select(task TaskSpec, candidates []WorkerSnapshot) int {
+	if len(candidates) == 1 {
+		return 0
+	}
+	a := rand.IntN(len(candidates))
+	b := rand.IntN(len(candidates) - 1)
+	if b >= a {
+		b++
+	}
+	if loadRatio(candidates[b]) < loadRatio(candidates[a]) {
+		return b
+	}
+	return a
+}
+
+// BinPack puts the task on the worker that would be the most utilized after
+// placing it, filling workers up before touching idle ones (keeps whole
+// machines free for large tasks or scale-down)
+type BinPack struct{}
+
+func (BinPack) Name() string { return StrategyBinPack }
+
+func (BinPack) Select(task TaskSpec, candidates []WorkerSnapshot) int {
+	return argmin(candidates, func(w WorkerSnapshot) float64 {
+		return -utilizationAfter(w, task.Requests)
+	})
+}
+
+// Spread puts the task on the worker that would be the least utilized after
+// placing it, so resource pressure is shared evenly
+type Spread struct{}
+
+func (Spread) Name() string { return StrategySpread }
+
+func (Spread) Select(task TaskSpec, candidates []WorkerSnapshot) int {
+	return argmin(candidates, func(w WorkerSnapshot) float64 {
+		return utilizationAfter(w, task.Requests)
+	})
+}
+
+// HealthScore picks the worker with the highest composite health score.
+// Load is part of the score, so traffic still spreads out, but flaky, slow
+// or overloaded workers get less of it. Ties go to the lowest worker ID.
+type HealthScore struct{}
+
+func (HealthScore) Name() string { return StrategyHealthScore }
+
+func (HealthScore) Select(task TaskSpec, candidates []WorkerSnapshot) int {
+	return argmin(candidates, func(w WorkerSnapshot) float64 {
+		return -w.Score
+	})
+}
+
+// utilizationAfter is the worker's resource utilization once request is
+// allocated; workers without advertised resources fall back to the slot ratio
+func utilizationAfter(w WorkerSnapshot, request Resources) float64 {
+	if u, ok := Utilization(w.Capacity, w.Allocated, request); ok {
+		return u
+	}
+	return float64(w.Load()+1) / float64(w.MaxConcurrency)
+}
+
+// loadRatio is the share of a worker's slots in use, including the phi penalty
+func loadRatio(w WorkerSnapshot) float64 {
+	return (float64(w.Load()) + w.Penalty) / float64(w.MaxConcurrency)
+}
+
+// argmin returns the index of the candidate with the lowest score; the first
+// one wins ties
+func argmin(candidates []WorkerSnapshot, score func(WorkerSnapshot) float64) int {
+	best, bestScore := 0, score(candidates[0])
+	for i := 1; i < len(candidates); i++ {
+		if s := score(candidates[i]); s < bestScore {
+			best, bestScore = i, s
+		}
+	}
+	return best
+}
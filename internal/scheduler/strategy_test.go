@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"testing"
+)
+
+func worker(id string, maxConcurrency, active int32) WorkerSnapshot {
+	return WorkerSnapshot{ID: id, MaxConcurrency: maxConcurrency, ActiveTaskCount: active}
+}
+
+func TestStrategySelect(t *testing.T) {
+	cpu := func(w WorkerSnapshot, capacity, allocated int64) WorkerSnapshot {
+		w.Capacity = Resources{CPUMillis: capacity}
+		w.Allocated = Resources{CPUMillis: allocated}
+		return w
+	}
+
+	tests := []struct {
+		name       string
+		strategy   Strategy
+		task       TaskSpec
+		candidates []WorkerSnapshot
+		want       string
+	}{
+		{"least-load picks fewest tasks", LeastLoad{}, TaskSpec{},
+			[]WorkerSnapshot{worker("a", 10, 3), worker("b", 10, 1), worker("c", 10, 2)}, "b"},
+		{"least-load ties go to lowest ID", LeastLoad{}, TaskSpec{},
+			[]WorkerSnapshot{worker("a", 10, 1), worker("b", 10, 1)}, "a"},
+		{"least-load counts the phi penalty", LeastLoad{}, TaskSpec{},
+			[]WorkerSnapshot{{ID: "a", MaxConcurrency: 10, Penalty: 3}, worker("b", 10, 2)}, "b"},
+		{"least-load-ratio favours big workers", LeastLoadRatio{}, TaskSpec{},
+			[]WorkerSnapshot{worker("a", 4, 2), worker("b", 16, 4)}, "b"},
+		{"bin-pack fills the busiest", BinPack{}, TaskSpec{Requests: Resources{CPUMillis: 500}},
+			[]WorkerSnapshot{cpu(worker("a", 10, 0), 4000, 0), cpu(worker("b", 10, 0), 4000, 2000)}, "b"},
+		{"spread picks the emptiest", Spread{}, TaskSpec{Requests: Resources{CPUMillis: 500}},
+			[]WorkerSnapshot{cpu(worker("a", 10, 0), 4000, 3000), cpu(worker("b", 10, 0), 4000, 1000)}, "b"},
+		{"spread falls back to slots", Spread{}, TaskSpec{},
+			[]WorkerSnapshot{worker("a", 4, 3), worker("b", 4, 1)}, "b"},
+		{"health-score picks the best score", HealthScore{}, TaskSpec{},
+			[]WorkerSnapshot{{ID: "a", Score: 0.4}, {ID: "b", Score: 0.9}, {ID: "c", Score: 0.7}}, "b"},
+		{"power-of-two with one candidate", PowerOfTwo{}, TaskSpec{},
+			[]WorkerSnapshot{worker("a", 4, 3)}, "a"},
+		{"weighted-random skips full workers", WeightedRandom{}, TaskSpec{},
+			[]WorkerSnapshot{worker("a", 4, 4), worker("b", 4, 0)}, "b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := tt.strategy.Select(tt.task, tt.candidates)
+			if got := tt.candidates[i].ID; got != tt.want {
+				t.Errorf("%s selected %s, want %s", tt.strategy.Name(), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPowerOfTwoPrefersLessLoaded(t *testing.T) {
+	// with two candidates both are always sampled
+	candidates := []WorkerSnapshot{worker("a", 4, 3), worker("b", 4, 1)}
+	for i := 0; i < 50; i++ {
+		if got := candidates[PowerOfTwo{}.Select(TaskSpec{}, candidates)].ID; got != "b" {
+			t.Fatalf("PowerOfTwo selected %s, want b", got)
+		}
+	}
+}
+
+func TestRoundRobinRotates(t *testing.T) {
+	rr := &RoundRobin{}
+	candidates := []WorkerSnapshot{worker("a", 1, 0), worker("b", 1, 0), worker("c", 1, 0)}
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, candidates[rr.Select(TaskSpec{}, candidates)].ID)
+	}
+	// "b" leaves; the rotation continues after the last chosen ID
+	candidates = []WorkerSnapshot{worker("a", 1, 0), worker("c", 1, 0)}
+	got = append(got, candidates[rr.Select(TaskSpec{}, candidates)].ID)
+
+	want := []string{"a", "b", "c", "a", "c"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("RoundRobin order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestNewStrategy(t *testing.T) {
+	for _, name := range StrategyNames() {
+		s, err := NewStrategy(name)
+		if err != nil {
+			t.Fatalf("NewStrategy(%q) = %v", name, err)
+		}
+		if s.Name() != name {
+			t.Errorf("NewStrategy(%q).Name() = %q", name, s.Name())
+		}
+	}
+	if _, err := NewStrategy("fastest"); err == nil {
+		t.Error("NewStrategy accepted an unknown name")
+	}
+}
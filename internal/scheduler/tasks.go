@@ -0,0 +1,456 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Errors returned by the TaskStore; match them with errors.Is
+var (
+	ErrTaskNotFound      = errors.New("task not found")
+	ErrTaskExists        = errors.New("task already exists")
+	ErrDuplicateTask     = errors.New("duplicate submission")
+	ErrIllegalTransition = errors.New("illegal task state transition")
+	ErrInvalidTimeout    = errors.New("invalid task timeout")
+)
+
+// defaultDedupeWindow is how long an idempotency key keeps matching the task
+// it was first submitted with
+const defaultDedupeWindow = 24 * time.Hour
+
+// defaultRetention is how long finished tasks are kept for status queries
+const defaultRetention = 24 * time.Hour
+
+// pruneInterval is how often the TaskStore looks for expired idempotency
+// keys and finished tasks past their retention
+const pruneInterval = time.Minute
+
+// NewTaskID returns a random task ID for tasks submitted without one
+func NewTaskID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "task-" + hex.EncodeToString(b)
+}
+
+// TaskState is a step in a task's lifecycle:
+//
+//	Pending -> Assigned -> Running -> Succeeded | Failed | Cancelled | TimedOut
+//
+// Assigned and Running tasks go back to Pending when their worker is lost or
+// when a failed attempt is retried.
+type TaskState int
+
+const (
+	TaskPending   TaskState = iota // waiting in the dispatch queue
+	TaskAssigned                   // sent to a worker, not started yet
+	TaskRunning                    // the worker reported it started
+	TaskSucceeded                  // finished without error
+	TaskFailed                     // finished with an error
+	TaskCancelled                  // cancelled before it finished
+	TaskTimedOut                   // did not finish in time
+)
+
+func (s TaskState) String() string {
+	switch s {
+	case TaskPending:
+		return "Pending"
+	case TaskAssigned:
+		return "Assigned"
+	case TaskRunning:
+		return "Running"
+	case TaskSucceeded:
+		return "Succeeded"
+	case TaskFailed:
+		return "Failed"
+	case TaskCancelled:
+		return "Cancelled"
+	case TaskTimedOut:
+		return "TimedOut"
+	default:
+		return "Unknown"
+	}
+}
+
+// Terminal reports whether no further transitions are possible
+func (s TaskState) Terminal() bool {
+	return s == TaskSucceeded || s == TaskFailed || s == TaskCancelled || s == TaskTimedOut
+}
+
+// allowedTransitions lists the legal state changes
+var allowedTransitions = map[TaskState][]TaskState{
+	// Pending -> Succeeded/Failed: late result of an attempt whose worker
+	// was considered lost
+	TaskPending:  {TaskAssigned, TaskSucceeded, TaskFailed, TaskCancelled, TaskTimedOut},
+	TaskAssigned: {TaskRunning, TaskPending, TaskSucceeded, TaskFailed, TaskCancelled, TaskTimedOut},
+	TaskRunning:  {TaskPending, TaskSucceeded, TaskFailed, TaskCancelled, TaskTimedOut},
+}
+
+func canTransition(from, to TaskState) bool {
+	for _, s := range allowedTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Task is the Master's record of one task
+type Task struct {
+	ID      string
+	Name    string
+	Payload []byte
+	Labels  map[string]string
+	// IdempotencyKey dedupes submissions within the TaskStore's window
+	IdempotencyKey string
+
+	State        TaskState
+	WorkerID     string // worker of the current/last attempt
+	Attempts     int32  // number of times the task was dispatched
+	FencingToken uint64 // token of the current/last attempt
+
+	CreatedAt  time.Time
+	AssignedAt time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	LastError string
+	Output    []byte
+	// NotBefore is set while a retry waits for its backoff
+	NotBefore time.Time
+}
+
+// TaskFilter selects tasks in ListTasks. Zero fields match everything.
+type TaskFilter struct {
+	States   []TaskState
+	WorkerID string
+	Name     string
+	Labels   map[string]string // every key must have exactly this value
+	Limit    int               // maximum number of tasks returned, 0 = no limit
+}
+
+func (f TaskFilter) matches(t *Task) bool {
+	if f.WorkerID != "" && t.WorkerID != f.WorkerID {
+		return false
+	}
+	if f.Name != "" && t.Name != f.Name {
+		return false
+	}
+	for k, v := range f.Labels {
+		if got, ok := t.Labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	if len(f.States) == 0 {
+		return true
+	}
+	for _, s := range f.States {
+		if t.State == s {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskStore tracks every task the Master knows about.
+// It is a thread-safe component; callers always get copies.
+type TaskStore struct {
+	mu       sync.RWMutex
+	tasks    map[string]*Task       // key is task_id
+	watchers map[string][]chan Task // key is task_id
+
+	// idempotency keys seen within the dedupe window
+	keys   map[string]dedupeEntry // key is the idempotency key
+	window time.Duration
+	// retention is how long finished tasks are kept; prune keeps them at
+	// least for window
+	retention time.Duration
+}
+
+// dedupeEntry maps an idempotency key to the task first submitted with it
+type dedupeEntry struct {
+	taskID  string
+	expires time.Time
+}
+
+// NewTaskStore constructs a TaskStore
+func NewTaskStore() *TaskStore {
+	return &TaskStore{
+		tasks:     make(map[string]*Task),
+		watchers:  make(map[string][]chan Task),
+		keys:      make(map[string]dedupeEntry),
+		window:    defaultDedupeWindow,
+		retention: defaultRetention,
+	}
+}
+
+// SetRetention sets how long tasks are kept once they reached a terminal
+// state; 0 keeps them forever. Tasks are kept at least for the dedupe window
+// whatever the retention, so a duplicate submission always finds the task it
+// matched.
+func (ts *TaskStore) SetRetention(retention time.Duration) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.retention = retention
+}
+
+// SetDedupeWindow sets how long an idempotency key keeps matching its task;
+// 0 disables deduplication. Keys already recorded keep their expiry.
+func (ts *TaskStore) SetDedupeWindow(window time.Duration) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.window = window
+}
+
+// Create adds a new Pending task. If key is set and a task was created with
+// the same key within the dedupe window, nothing is created and that task is
+// returned with ErrDuplicateTask.
+func (ts *TaskStore) Create(id, name string, payload []byte, labels map[string]string, key string) (Task, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	now := time.Now()
+	if key != "" && ts.window > 0 {
+		if e, ok := ts.keys[key]; ok && now.Before(e.expires) {
+			if t, ok := ts.tasks[e.taskID]; ok {
+				return *t, fmt.Errorf("idempotency key %q of task %s: %w", key, e.taskID, ErrDuplicateTask)
+			}
+		}
+	}
+	if _, ok := ts.tasks[id]; ok {
+		return Task{}, fmt.Errorf("task %s: %w", id, ErrTaskExists)
+	}
+	t := &Task{
+		ID:             id,
+		Name:           name,
+		Payload:        payload,
+		Labels:         labels,
+		IdempotencyKey: key,
+		State:          TaskPending,
+		CreatedAt:      now,
+	}
+	ts.tasks[id] = t
+	if key != "" && ts.window > 0 {
+		ts.keys[key] = dedupeEntry{taskID: id, expires: now.Add(ts.window)}
+	}
+	return *t, nil
+}
+
+// FindByKey returns the task created with an idempotency key within the
+// dedupe window
+func (ts *TaskStore) FindByKey(key string) (Task, bool) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	e, ok := ts.keys[key]
+	if !ok || !time.Now().Before(e.expires) {
+		return Task{}, false
+	}
+	t, ok := ts.tasks[e.taskID]
+	if !ok {
+		return Task{}, false
+	}
+	return *t, true
+}
+
+// StartPruner launches the background goroutine that prunes the store every
+// pruneInterval. It stops when ctx is cancelled.
+func (ts *TaskStore) StartPruner(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				ts.prune(now)
+			}
+		}
+	}()
+}
+
+// prune forgets expired idempotency keys and finished tasks past their
+// retention
+func (ts *TaskStore) prune(now time.Time) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	for key, e := range ts.keys {
+		if !now.Before(e.expires) {
+			delete(ts.keys, key)
+		}
+	}
+	if ts.retention <= 0 {
+		return
+	}
+	cutoff := now.Add(-max(ts.retention, ts.window))
+	for id, t := range ts.tasks {
+		if t.State.Terminal() && t.FinishedAt.Before(cutoff) {
+			delete(ts.tasks, id)
+		}
+	}
+}
+
+// GetTask returns a copy of a task
+func (ts *TaskStore) GetTask(id string) (Task, bool) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	t, ok := ts.tasks[id]
+	if !ok {
+		return Task{}, false
+	}
+	return *t, true
+}
+
+// ListTasks returns copies of the tasks matching filter, oldest first
+func (ts *TaskStore) ListTasks(filter TaskFilter) []Task {
+	ts.mu.RLock()
+	out := make([]Task, 0)
+	for _, t := range ts.tasks {
+		if filter.matches(t) {
+			out = append(out, *t)
+		}
+	}
+	ts.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	if filter.Limit > 0 && len(out) > filter.Limit {
+		out = out[:filter.Limit]
+	}
+	return out
+}
+
+// transition moves a task to a new state and lets update fill in the details;
+// update still sees the old state. Callers must not hold ts.mu.
+func (ts *TaskStore) transition(id string, to TaskState, update func(t *Task)) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	t, ok := ts.tasks[id]
+	if !ok {
+		return fmt.Errorf("task %s: %w", id, ErrTaskNotFound)
+	}
+	if !canTransition(t.State, to) {
+		return fmt.Errorf("task %s: %w %s -> %s", id, ErrIllegalTransition, t.State, to)
+	}
+	if update != nil {
+		update(t)
+	}
+	t.State = to
+	ts.notify(t)
+	return nil
+}
+
+// Watch returns a channel that receives a copy of the task after every state
+// change. Only the latest state is buffered, so slow readers skip
+// intermediate states but never miss the final one. Call stop when done.
+func (ts *TaskStore) Watch(id string) (updates <-chan Task, stop func(), err error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if _, ok := ts.tasks[id]; !ok {
+		return nil, nil, fmt.Errorf("task %s: %w", id, ErrTaskNotFound)
+	}
+	ch := make(chan Task, 1)
+	ts.watchers[id] = append(ts.watchers[id], ch)
+
+	stop = func() {
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		list := ts.watchers[id]
+		for i, c := range list {
+			if c == ch {
+				ts.watchers[id] = append(list[:i], list[i+1:]...)
+				break
+			}
+		}
+		if len(ts.watchers[id]) == 0 {
+			delete(ts.watchers, id)
+		}
+	}
+	return ch, stop, nil
+}
+
+// notify pushes the task's new state to its watchers, replacing any state
+// they have not read yet. Callers must hold ts.mu.
+func (ts *TaskStore) notify(t *Task) {
+	for _, ch := range ts.watchers[t.ID] {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- *t
+	}
+}
+
+// MarkAssigned records that an attempt was sent to a worker
+func (ts *TaskStore) MarkAssigned(id, workerID string, attempt int32, token uint64) error {
+	return ts.transition(id, TaskAssigned, func(t *Task) {
+		t.WorkerID = workerID
+		t.Attempts = attempt
+		t.FencingToken = token
+		t.AssignedAt = time.Now()
+		t.StartedAt = time.Time{}
+		t.NotBefore = time.Time{}
+	})
+}
+
+// MarkRunning records that the worker started the task
+func (ts *TaskStore) MarkRunning(id string, startedAt time.Time) error {
+	return ts.transition(id, TaskRunning, func(t *Task) {
+		t.StartedAt = startedAt
+	})
+}
+
+// MarkPending puts a task back to Pending, e.g. after its worker was lost
+func (ts *TaskStore) MarkPending(id, reason string) error {
+	return ts.transition(id, TaskPending, func(t *Task) {
+		t.LastError = reason
+	})
+}
+
+// ScheduleRetry puts a failed task back to Pending; it is dispatched again
+// once notBefore has passed
+func (ts *TaskStore) ScheduleRetry(id, reason string, notBefore time.Time) error {
+	return ts.transition(id, TaskPending, func(t *Task) {
+		t.LastError = reason
+		t.NotBefore = notBefore
+	})
+}
+
+// Finish moves a task to a terminal state
+func (ts *TaskStore) Finish(id string, state TaskState, output []byte, errMsg string, finishedAt time.Time) error {
+	if !state.Terminal() {
+		return fmt.Errorf("task %s: %s is not a terminal state", id, state)
+	}
+	return ts.transition(id, state, func(t *Task) {
+		t.Output = output
+		t.FinishedAt = finishedAt
+		if errMsg != "" {
+			t.LastError = errMsg
+		}
+	})
+}
+
+// Cancel moves an unfinished task to Cancelled and returns it as it was
+// before, so the caller knows whether a worker has to be told
+func (ts *TaskStore) Cancel(id, reason string, cancelledAt time.Time) (before Task, err error) {
+	err = ts.transition(id, TaskCancelled, func(t *Task) {
+		before = *t
+		t.FinishedAt = cancelledAt
+		t.LastError = reason
+	})
+	return before, err
+}
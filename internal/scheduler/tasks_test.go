@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTaskTransitions(t *testing.T) {
+	now := time.Now()
+	assign := func(ts *TaskStore, id string) error { return ts.MarkAssigned(id, "w1", 1, 7) }
+	run := func(ts *TaskStore, id string) error { return ts.MarkRunning(id, now) }
+	pend := func(ts *TaskStore, id string) error { return ts.MarkPending(id, "worker lost") }
+	retry := func(ts *TaskStore, id string) error { return ts.ScheduleRetry(id, "failed", now.Add(time.Second)) }
+	succeed := func(ts *TaskStore, id string) error { return ts.Finish(id, TaskSucceeded, nil, "", now) }
+	fail := func(ts *TaskStore, id string) error { return ts.Finish(id, TaskFailed, nil, "boom", now) }
+	cancel := func(ts *TaskStore, id string) error { _, err := ts.Cancel(id, "cancelled", now); return err }
+
+	tests := []struct {
+		name  string
+		steps []func(*TaskStore, string) error
+		want  TaskState
+		err   error // error of the last step
+	}{
+		{"assign", []func(*TaskStore, string) error{assign}, TaskAssigned, nil},
+		{"run to success", []func(*TaskStore, string) error{assign, run, succeed}, TaskSucceeded, nil},
+		{"fail while assigned", []func(*TaskStore, string) error{assign, fail}, TaskFailed, nil},
+		{"requeue while running", []func(*TaskStore, string) error{assign, run, pend}, TaskPending, nil},
+		{"retry then reassign", []func(*TaskStore, string) error{assign, run, retry, assign}, TaskAssigned, nil},
+		{"cancel pending", []func(*TaskStore, string) error{cancel}, TaskCancelled, nil},
+		{"late result while pending", []func(*TaskStore, string) error{assign, pend, succeed}, TaskSucceeded, nil},
+		{"run without assignment", []func(*TaskStore, string) error{run}, TaskPending, ErrIllegalTransition},
+		{"pending to pending", []func(*TaskStore, string) error{pend}, TaskPending, ErrIllegalTransition},
+		{"terminal is final", []func(*TaskStore, string) error{assign, succeed, pend}, TaskSucceeded, ErrIllegalTransition},
+		{"no cancel after success", []func(*TaskStore, string) error{assign, succeed, cancel}, TaskSucceeded, ErrIllegalTransition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := NewTaskStore()
+			if _, err := ts.Create("t1", "job", nil, nil, ""); err != nil {
+				t.Fatal(err)
+			}
+			var err error
+			for i, step := range tt.steps {
+				if err = step(ts, "t1"); err != nil && i < len(tt.steps)-1 {
+					t.Fatalf("step %d: %v", i, err)
+				}
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("last step = %v, want %v", err, tt.err)
+			}
+			if got, _ := ts.GetTask("t1"); got.State != tt.want {
+				t.Errorf("state = %s, want %s", got.State, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskStoreErrors(t *testing.T) {
+	ts := NewTaskStore()
+	if err := ts.MarkRunning("missing", time.Now()); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("MarkRunning(missing) = %v, want ErrTaskNotFound", err)
+	}
+	if _, err := ts.Create("t1", "job", nil, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Create("t1", "job", nil, nil, ""); !errors.Is(err, ErrTaskExists) {
+		t.Errorf("Create(t1) twice = %v, want ErrTaskExists", err)
+	}
+	if err := ts.Finish("t1", TaskRunning, nil, "", time.Now()); err == nil {
+		t.Error("Finish accepted a non-terminal state")
+	}
+}
+
+func TestTaskDedupe(t *testing.T) {
+	ts := NewTaskStore()
+	first, err := ts.Create("t1", "job", []byte("a"), nil, "key-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dup, err := ts.Create("t2", "job", []byte("b"), nil, "key-1")
+	if !errors.Is(err, ErrDuplicateTask) || dup.ID != first.ID {
+		t.Fatalf("duplicate Create = %s, %v; want %s, ErrDuplicateTask", dup.ID, err, first.ID)
+	}
+	if _, ok := ts.GetTask("t2"); ok {
+		t.Error("duplicate Create stored a task")
+	}
+	if got, ok := ts.FindByKey("key-1"); !ok || got.ID != "t1" {
+		t.Errorf("FindByKey = %s, %v; want t1", got.ID, ok)
+	}
+
+	// a finished task still matches its key
+	if err := ts.MarkAssigned("t1", "w1", 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.Finish("t1", TaskSucceeded, nil, "", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if dup, err := ts.Create("t3", "job", nil, nil, "key-1"); !errors.Is(err, ErrDuplicateTask) || dup.State != TaskSucceeded {
+		t.Errorf("Create after finish = %s, %v; want the Succeeded t1", dup.State, err)
+	}
+
+	// other keys and key-less tasks are never deduplicated
+	if _, err := ts.Create("t4", "job", nil, nil, "key-2"); err != nil {
+		t.Errorf("Create(key-2) = %v", err)
+	}
+	if _, err := ts.Create("t5", "job", nil, nil, ""); err != nil {
+		t.Errorf("Create without key = %v", err)
+	}
+
+	// an expired key matches nothing
+	ts.mu.Lock()
+	ts.keys["key-1"] = dedupeEntry{taskID: "t1", expires: time.Now().Add(-time.Second)}
+	ts.mu.Unlock()
+	if _, ok := ts.FindByKey("key-1"); ok {
+		t.Error("FindByKey matched an expired key")
+	}
+	if _, err := ts.Create("t6", "job", nil, nil, "key-1"); err != nil {
+		t.Errorf("Create after expiry = %v", err)
+	}
+}
+
+func TestTaskDedupeDisabled(t *testing.T) {
+	ts := NewTaskStore()
+	ts.SetDedupeWindow(0)
+	if _, err := ts.Create("t1", "job", nil, nil, "key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Create("t2", "job", nil, nil, "key"); err != nil {
+		t.Errorf("Create with dedupe disabled = %v", err)
+	}
+}
+
+func TestTaskRetention(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		retention time.Duration
+		window    time.Duration
+		finished  time.Duration // how long ago the task finished
+		kept      bool
+	}{
+		{"within retention", time.Hour, time.Minute, 30 * time.Minute, true},
+		{"past retention", time.Hour, time.Minute, 2 * time.Hour, false},
+		{"kept for the dedupe window", time.Minute, time.Hour, 30 * time.Minute, true},
+		{"kept forever", 0, time.Minute, 1000 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := NewTaskStore()
+			ts.SetRetention(tt.retention)
+			ts.SetDedupeWindow(tt.window)
+			for _, id := range []string{"done", "running"} {
+				if _, err := ts.Create(id, "job", nil, nil, ""); err != nil {
+					t.Fatal(err)
+				}
+				if err := ts.MarkAssigned(id, "w1", 1, 1); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := ts.Finish("done", TaskFailed, nil, "boom", now.Add(-tt.finished)); err != nil {
+				t.Fatal(err)
+			}
+
+			ts.prune(now)
+
+			if _, ok := ts.GetTask("done"); ok != tt.kept {
+				t.Errorf("finished task kept = %v, want %v", ok, tt.kept)
+			}
+			if _, ok := ts.GetTask("running"); !ok {
+				t.Error("pruned a task that has not finished")
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// settlement is an Ack or Nack the fake channel received
+type settlement struct {
+	tag     uint64
+	ack     bool
+	requeue bool
+}
+
+// fakeChannel is an in-process AMQPChannel that hands out the deliveries
+// pushed to it and records how they were settled
+type fakeChannel struct {
+	deliveries chan amqp.Delivery
+
+	mu      sync.Mutex
+	settled []settlement
+	nackErr error
+}
+
+func newFakeChannel() *fakeChannel {
+	return &fakeChannel{deliveries: make(chan amqp.Delivery, 16)}
+}
+
+func (c *fakeChannel) Qos(prefetchCount, prefetchSize int, global bool) error { return nil }
+
+func (c *fakeChannel) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	return c.deliveries, nil
+}
+
+func (c *fakeChannel) Ack(tag uint64, multiple bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.settled = append(c.settled, settlement{tag: tag, ack: true})
+	return nil
+}
+
+func (c *fakeChannel) Nack(tag uint64, multiple bool, requeue bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.settled = append(c.settled, settlement{tag: tag, requeue: requeue})
+	return c.nackErr
+}
+
+func (c *fakeChannel) Close() error {
+	close(c.deliveries)
+	return nil
+}
+
+func (c *fakeChannel) settlements() []settlement {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]settlement(nil), c.settled...)
+}
+
+func newTestSource(t *testing.T) (*AMQPSource, *fakeChannel) {
+	t.Helper()
+	ch := newFakeChannel()
+	s, err := NewAMQPSource(ch, AMQPConfig{Queue: "tasks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, ch
+}
+
+func next(t *testing.T, s *AMQPSource) (*pb.TaskAssignment, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return s.Next(ctx)
+}
+
+func TestAMQPToTask(t *testing.T) {
+	tests := []struct {
+		name     string
+		delivery amqp.Delivery
+		valid    bool
+		check    func(t *testing.T, task *pb.TaskAssignment)
+	}{
+		{"properties", amqp.Delivery{MessageId: "t1", Type: "resize", Body: []byte("img")}, true,
+			func(t *testing.T, task *pb.TaskAssignment) {
+				if task.TaskId != "t1" || task.TaskName != "resize" || string(task.TaskPayload) != "img" {
+					t.Errorf("task = %v", task)
+				}
+			}},
+		{"headers", amqp.Delivery{Headers: amqp.Table{"task_id": "t2", "task_name": "resize"}}, true,
+			func(t *testing.T, task *pb.TaskAssignment) {
+				if task.TaskId != "t2" || task.TaskName != "resize" {
+					t.Errorf("task = %v", task)
+				}
+			}},
+		{"properties win over headers", amqp.Delivery{MessageId: "t3", Type: "a", Headers: amqp.Table{"task_id": "x", "task_name": "b"}}, true,
+			func(t *testing.T, task *pb.TaskAssignment) {
+				if task.TaskId != "t3" || task.TaskName != "a" {
+					t.Errorf("task = %v", task)
+				}
+			}},
+		{"optional headers", amqp.Delivery{MessageId: "t4", Type: "train", Headers: amqp.Table{
+			"placement":       `{"nodeSelector": {"zone": "us-east"}}`,
+			"requests":        []byte(`{"cpuMillis": "500", "named": {"gpu": "1"}}`),
+			"retry_policy":    `{"maxAttempts": 3}`,
+			"labels":          `{"team": "ml"}`,
+			"timeout_millis":  int32(60000),
+			"idempotency_key": "k1",
+		}}, true,
+			func(t *testing.T, task *pb.TaskAssignment) {
+				if task.Placement.GetNodeSelector()["zone"] != "us-east" {
+					t.Errorf("placement = %v", task.Placement)
+				}
+				if task.Requests.GetCpuMillis() != 500 || task.Requests.GetNamed()["gpu"] != 1 {
+					t.Errorf("requests = %v", task.Requests)
+				}
+				if task.RetryPolicy.GetMaxAttempts() != 3 {
+					t.Errorf("retry policy = %v", task.RetryPolicy)
+				}
+				if task.Labels["team"] != "ml" || task.IdempotencyKey != "k1" {
+					t.Errorf("labels = %v, key = %q", task.Labels, task.IdempotencyKey)
+				}
+				if task.TimeoutMillis != 60000 {
+					t.Errorf("timeout = %d ms", task.TimeoutMillis)
+				}
+			}},
+		{"no task ID", amqp.Delivery{Type: "resize"}, false, nil},
+		{"no task name", amqp.Delivery{MessageId: "t5"}, false, nil},
+		{"malformed placement", amqp.Delivery{MessageId: "t6", Type: "a", Headers: amqp.Table{"placement": `{"zone": 1}`}}, false, nil},
+		{"malformed labels", amqp.Delivery{MessageId: "t7", Type: "a", Headers: amqp.Table{"labels": `["a"]`}}, false, nil},
+		{"non-string header", amqp.Delivery{MessageId: "t8", Type: "a", Headers: amqp.Table{"requests": int32(5)}}, false, nil},
+		{"non-integer timeout", amqp.Delivery{MessageId: "t9", Type: "a", Headers: amqp.Table{"timeout_millis": "60s"}}, false, nil},
+		{"negative timeout", amqp.Delivery{MessageId: "t10", Type: "a", Headers: amqp.Table{"timeout_millis": int64(-1)}}, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := toTask(tt.delivery)
+			if tt.valid != (err == nil) {
+				t.Fatalf("toTask() error = %v, valid %v", err, tt.valid)
+			}
+			if tt.check != nil {
+				tt.check(t, task)
+			}
+		})
+	}
+}
+
+func TestAMQPRejectsMalformed(t *testing.T) {
+	s, ch := newTestSource(t)
+	ch.deliveries <- amqp.Delivery{DeliveryTag: 1, Type: "resize"} // no ID
+	ch.deliveries <- amqp.Delivery{DeliveryTag: 2, MessageId: "t1", Type: "resize"}
+
+	task, err := next(t, s)
+	if err != nil || task.TaskId != "t1" {
+		t.Fatalf("Next() = %v, %v; want t1", task, err)
+	}
+	want := []settlement{{tag: 1, requeue: false}}
+	if got := ch.settlements(); len(got) != 1 || got[0] != want[0] {
+		t.Errorf("settlements = %v, want %v", got, want)
+	}
+}
+
+func TestAMQPSettle(t *testing.T) {
+	tests := []struct {
+		name   string
+		settle func(s *AMQPSource) error
+		want   settlement
+	}{
+		{"ack", func(s *AMQPSource) error { return s.Ack("t1") }, settlement{tag: 7, ack: true}},
+		{"nack requeue", func(s *AMQPSource) error { return s.Nack("t1", true) }, settlement{tag: 7, requeue: true}},
+		{"nack drop", func(s *AMQPSource) error { return s.Nack("t1", false) }, settlement{tag: 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ch := newTestSource(t)
+			ch.deliveries <- amqp.Delivery{DeliveryTag: 7, MessageId: "t1", Type: "resize"}
+			if _, err := next(t, s); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.settle(s); err != nil {
+				t.Fatal(err)
+			}
+			if got := ch.settlements(); len(got) != 1 || got[0] != tt.want {
+				t.Errorf("settlements = %v, want %v", got, tt.want)
+			}
+			// the delivery is settled once only
+			if err := s.Ack("t1"); err == nil {
+				t.Error("second Ack succeeded")
+			}
+		})
+	}
+}
+
+func TestAMQPRedelivery(t *testing.T) {
+	tests := []struct {
+		name   string
+		settle func(s *AMQPSource) error
+		want   []settlement
+	}{
+		{"ack settles both", func(s *AMQPSource) error { return s.Ack("t1") },
+			[]settlement{{tag: 1, ack: true}, {tag: 2, ack: true}}},
+		{"nack returns one", func(s *AMQPSource) error { return s.Nack("t1", true) },
+			[]settlement{{tag: 1, ack: true}, {tag: 2, requeue: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ch := newTestSource(t)
+			// the same task published twice, e.g. by a retrying producer
+			ch.deliveries <- amqp.Delivery{DeliveryTag: 1, MessageId: "t1", Type: "resize"}
+			ch.deliveries <- amqp.Delivery{DeliveryTag: 2, MessageId: "t1", Type: "resize"}
+			for i := 0; i < 2; i++ {
+				if task, err := next(t, s); err != nil || task.TaskId != "t1" {
+					t.Fatalf("Next() = %v, %v", task, err)
+				}
+			}
+
+			if err := tt.settle(s); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Ack("t1"); err == nil {
+				t.Error("second Ack succeeded")
+			}
+			got := ch.settlements()
+			if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+				t.Errorf("settlements = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAMQPNackError(t *testing.T) {
+	s, ch := newTestSource(t)
+	ch.nackErr = errors.New("channel closed")
+	ch.deliveries <- amqp.Delivery{DeliveryTag: 3, MessageId: "t1", Type: "resize"}
+	if _, err := next(t, s); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Nack("t1", true); !errors.Is(err, ch.nackErr) {
+		t.Errorf("Nack() = %v, want %v", err, ch.nackErr)
+	}
+}
+
+func TestAMQPClose(t *testing.T) {
+	s, _ := newTestSource(t)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := next(t, s); !errors.Is(err, ErrClosed) {
+		t.Errorf("Next() after Close = %v, want ErrClosed", err)
+	}
+
+	s, _ = newTestSource(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.Next(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Next() with a cancelled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestAMQPReconnect(t *testing.T) {
+	s, first := newTestSource(t)
+	second := newFakeChannel()
+	dials := 0
+	s.cfg.ReconnectBaseDelay = time.Millisecond
+	s.dial = func() (AMQPChannel, io.Closer, error) {
+		// the broker is still down on the first attempt
+		if dials++; dials == 1 {
+			return nil, nil, errors.New("connection refused")
+		}
+		return second, io.NopCloser(nil), nil
+	}
+
+	first.deliveries <- amqp.Delivery{DeliveryTag: 1, MessageId: "t1", Type: "resize"}
+	if _, err := next(t, s); err != nil {
+		t.Fatal(err)
+	}
+
+	// the broker closes the channel and requeues t1 on the new one
+	close(first.deliveries)
+	second.deliveries <- amqp.Delivery{DeliveryTag: 1, MessageId: "t1", Type: "resize", Redelivered: true}
+	if task, err := next(t, s); err != nil || task.TaskId != "t1" {
+		t.Fatalf("Next() after the channel closed = %v, %v; want t1", task, err)
+	}
+	if dials != 2 {
+		t.Errorf("dialled %d times, want 2", dials)
+	}
+
+	// only the delivery on the new channel is left to settle
+	if err := s.Ack("t1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Ack("t1"); err == nil {
+		t.Error("stale delivery of the old channel was settled")
+	}
+	if got := second.settlements(); len(got) != 1 || got[0] != (settlement{tag: 1, ack: true}) {
+		t.Errorf("settlements = %v, want an ack of tag 1", got)
+	}
+	if got := first.settlements(); len(got) != 0 {
+		t.Errorf("settled %v on the closed channel", got)
+	}
+
+	// Close stops reconnecting
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := next(t, s); !errors.Is(err, ErrClosed) {
+		t.Errorf("Next() after Close = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,233 @@
+package source
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/YilinZhang0101/SwiftScheduler/internal/scheduler"
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// maxLineSize bounds one NDJSON record
+const maxLineSize = 4 << 20
+
+// fileRecord is one line of a task file
+type fileRecord struct {
+	TaskID   string `json:"task_id"`
+	TaskName string `json:"task_name"`
+	// Payload is used as is when it is a JSON string; any other JSON value
+	// (object, array, number) is passed on in its JSON encoding
+	Payload       json.RawMessage `json:"payload"`
+	PayloadBase64 string          `json:"payload_base64"`
+	// Placement and Requests are Placement and Resources messages in
+	// protobuf JSON form
+	Placement json.RawMessage `json:"placement"`
+	Requests  json.RawMessage `json:"requests"`
+	// Labels tag the task so clients can select it later, e.g. to cancel it
+	Labels map[string]string `json:"labels"`
+	// TimeoutMillis limits the task's execution time, 0 = none
+	TimeoutMillis int64 `json:"timeout_millis"`
+	// RetryPolicy is a RetryPolicy message in protobuf JSON form
+	RetryPolicy json.RawMessage `json:"retry_policy"`
+	// IdempotencyKey suppresses duplicates of the task, e.g. when a file is
+	// fed to the master twice
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+// FileSource reads tasks from newline-delimited JSON, one task per line:
+//
+//	{"task_id": "t1", "task_name": "echo", "payload": "hello"}
+//	{"task_name": "resize", "payload": {"w": 64, "h": 64}}
+//	{"task_name": "upload", "payload_base64": "aGVsbG8="}
+//	{"task_name": "train", "placement": {"nodeSelector": {"gpu": "true"}}}
+//	{"task_name": "encode", "requests": {"cpuMillis": "2000", "named": {"gpu": "1"}}}
+//	{"task_name": "report", "labels": {"job": "nightly"}, "timeout_millis": 60000}
+//	{"task_name": "fetch", "retry_policy": {"maxAttempts": 5, "backoffBaseMillis": "1000"}}
+//	{"task_name": "invoice", "idempotency_key": "invoice-2024-06"}
+//
+// task_id is generated when missing. Malformed lines are logged and skipped.
+// Next returns ErrClosed after the end of the input once every task was acked
+// or nacked; tasks nacked with requeue are handed out again.
+type FileSource struct {
+	*MemorySource
+	name   string
+	closer io.Closer // nil for stdin
+}
+
+// OpenFile reads tasks from a file, or from stdin when path is "-"
+func OpenFile(path string) (*FileSource, error) {
+	if path == "-" {
+		return NewFileSource(os.Stdin, "stdin"), nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open task file: %w", err)
+	}
+	s := NewFileSource(f, path)
+	s.closer = f
+	return s, nil
+}
+
+// NewFileSource reads tasks from r in the background; name is used in logs
+func NewFileSource(r io.Reader, name string) *FileSource {
+	s := &FileSource{
+		MemorySource: NewMemorySource(),
+		name:         name,
+	}
+	go s.read(r)
+	return s
+}
+
+// Close closes the underlying file
+func (s *FileSource) Close() error {
+	s.MemorySource.Close()
+	if s.closer != nil {
+		return s.closer.Close()
+	}
+	return nil
+}
+
+// read queues every valid line of r, then closes the queue
+func (s *FileSource) read(r io.Reader) {
+	defer s.MemorySource.Close()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	line, count := 0, 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		task, err := parseRecord(scanner.Bytes())
+		if err != nil {
+			log.Printf("[FileSource] %s:%d: skipping record: %v", s.name, line, err)
+			continue
+		}
+		if err := s.Push(task); err != nil {
+			return
+		}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("[FileSource] %s: read failed after line %d: %v", s.name, line, err)
+	}
+	log.Printf("[FileSource] %s: queued %d tasks", s.name, count)
+}
+
+// parseRecord maps one NDJSON line to a TaskAssignment
+func parseRecord(b []byte) (*pb.TaskAssignment, error) {
+	var rec fileRecord
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return nil, err
+	}
+	if rec.TaskName == "" {
+		return nil, errors.New("task_name is required")
+	}
+	if rec.TaskID == "" {
+		rec.TaskID = scheduler.NewTaskID()
+	}
+
+	var payload []byte
+	switch {
+	case rec.PayloadBase64 != "" && len(rec.Payload) > 0:
+		return nil, errors.New("payload and payload_base64 are mutually exclusive")
+	case rec.PayloadBase64 != "":
+		var err error
+		if payload, err = base64.StdEncoding.DecodeString(rec.PayloadBase64); err != nil {
+			return nil, fmt.Errorf("invalid payload_base64: %w", err)
+		}
+	case len(rec.Payload) > 0 && rec.Payload[0] == '"':
+		var str string
+		if err := json.Unmarshal(rec.Payload, &str); err != nil {
+			return nil, err
+		}
+		payload = []byte(str)
+	case len(rec.Payload) > 0 && string(rec.Payload) != "null":
+		payload = rec.Payload
+	}
+
+	placement, err := parsePlacement(rec.Placement)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := parseResources(rec.Requests)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := parseRetryPolicy(rec.RetryPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.TaskAssignment{
+		TaskId:         rec.TaskID,
+		TaskName:       rec.TaskName,
+		TaskPayload:    payload,
+		Placement:      placement,
+		Requests:       requests,
+		Labels:         rec.Labels,
+		TimeoutMillis:  rec.TimeoutMillis,
+		RetryPolicy:    policy,
+		IdempotencyKey: rec.IdempotencyKey,
+	}, nil
+}
+
+// parsePlacement decodes a Placement in protobuf JSON form; empty input means
+// no placement
+func parsePlacement(b []byte) (*pb.Placement, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return nil, nil
+	}
+	p := &pb.Placement{}
+	if err := protojson.Unmarshal(b, p); err != nil {
+		return nil, fmt.Errorf("invalid placement: %w", err)
+	}
+	return p, nil
+}
+
+// parseRetryPolicy decodes a RetryPolicy in protobuf JSON form; empty input
+// means the master's default policy
+func parseRetryPolicy(b []byte) (*pb.RetryPolicy, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return nil, nil
+	}
+	p := &pb.RetryPolicy{}
+	if err := protojson.Unmarshal(b, p); err != nil {
+		return nil, fmt.Errorf("invalid retry_policy: %w", err)
+	}
+	return p, nil
+}
+
+// parseLabels decodes a JSON object of string labels; empty input means no
+// labels
+func parseLabels(b []byte) (map[string]string, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return nil, nil
+	}
+	var labels map[string]string
+	if err := json.Unmarshal(b, &labels); err != nil {
+		return nil, fmt.Errorf("invalid labels: %w", err)
+	}
+	return labels, nil
+}
+
+// parseResources decodes Resources in protobuf JSON form; empty input means
+// no requests
+func parseResources(b []byte) (*pb.Resources, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return nil, nil
+	}
+	r := &pb.Resources{}
+	if err := protojson.Unmarshal(b, r); err != nil {
+		return nil, fmt.Errorf("invalid requests: %w", err)
+	}
+	return r, nil
+}
@@ -0,0 +1,89 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		payload string
+		valid   bool
+	}{
+		{"string payload", `{"task_id": "t1", "task_name": "echo", "payload": "hello"}`, "hello", true},
+		{"object payload", `{"task_id": "t1", "task_name": "resize", "payload": {"w": 64}}`, `{"w": 64}`, true},
+		{"number payload", `{"task_id": "t1", "task_name": "sleep", "payload": 5}`, "5", true},
+		{"null payload", `{"task_id": "t1", "task_name": "noop", "payload": null}`, "", true},
+		{"no payload", `{"task_id": "t1", "task_name": "noop"}`, "", true},
+		{"base64 payload", `{"task_id": "t1", "task_name": "upload", "payload_base64": "aGVsbG8="}`, "hello", true},
+		{"both payloads", `{"task_id": "t1", "task_name": "upload", "payload": "x", "payload_base64": "aGVsbG8="}`, "", false},
+		{"invalid base64", `{"task_id": "t1", "task_name": "upload", "payload_base64": "%%%"}`, "", false},
+		{"no task name", `{"task_id": "t1", "payload": "x"}`, "", false},
+		{"invalid placement", `{"task_id": "t1", "task_name": "train", "placement": {"nodeSelector": 1}}`, "", false},
+		{"invalid requests", `{"task_id": "t1", "task_name": "train", "requests": {"cpuMillis": "lots"}}`, "", false},
+		{"invalid retry policy", `{"task_id": "t1", "task_name": "fetch", "retry_policy": {"maxAttempts": "x"}}`, "", false},
+		{"not JSON", `task_name=echo`, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := parseRecord([]byte(tt.line))
+			if (err == nil) != tt.valid {
+				t.Fatalf("parseRecord() error = %v, want valid %v", err, tt.valid)
+			}
+			if !tt.valid {
+				return
+			}
+			if task.TaskId != "t1" || string(task.TaskPayload) != tt.payload {
+				t.Errorf("task %s payload = %q, want t1 with %q", task.TaskId, task.TaskPayload, tt.payload)
+			}
+		})
+	}
+}
+
+func TestParseRecordFields(t *testing.T) {
+	task, err := parseRecord([]byte(`{"task_name": "train", "placement": {"nodeSelector": {"gpu": "true"}},
+		"requests": {"cpuMillis": "2000"}, "labels": {"job": "nightly"}, "timeout_millis": 60000,
+		"retry_policy": {"maxAttempts": 5}, "idempotency_key": "k1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.TaskId == "" {
+		t.Error("no task ID generated")
+	}
+	if task.Placement.GetNodeSelector()["gpu"] != "true" || task.Requests.GetCpuMillis() != 2000 {
+		t.Errorf("placement %v, requests %v", task.Placement, task.Requests)
+	}
+	if task.Labels["job"] != "nightly" || task.TimeoutMillis != 60000 || task.RetryPolicy.GetMaxAttempts() != 5 || task.IdempotencyKey != "k1" {
+		t.Errorf("task = %v", task)
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	input := `{"task_id": "t1", "task_name": "echo"}
+
+not json
+{"task_id": "t2", "task_name": "echo"}
+`
+	s := NewFileSource(strings.NewReader(input), "tasks.ndjson")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// the malformed line is skipped
+	for _, want := range []string{"t1", "t2"} {
+		task, err := s.Next(ctx)
+		if err != nil || task.TaskId != want {
+			t.Fatalf("Next() = %v, %v; want %s", task, err, want)
+		}
+		if err := s.Ack(want); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.Next(ctx); !errors.Is(err, ErrClosed) {
+		t.Errorf("Next() at the end of the input = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,85 @@
+package source
+
+import (
+	"context"
+	"sync"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// GRPCSource carries tasks submitted through the TaskService API. Submit
+// blocks until the Dispatcher accepted or rejected the task, so the RPC can
+// answer with the outcome. Tasks are not acknowledged to anyone: the caller
+// follows them through the task store.
+type GRPCSource struct {
+	*MemorySource
+
+	mu      sync.Mutex
+	waiters map[string][]chan error // key is task_id, in submit order
+}
+
+// NewGRPCSource constructs a GRPCSource
+func NewGRPCSource() *GRPCSource {
+	return &GRPCSource{
+		MemorySource: NewMemorySource(),
+		waiters:      make(map[string][]chan error),
+	}
+}
+
+// Submit queues a task and waits until the Dispatcher took it
+func (s *GRPCSource) Submit(ctx context.Context, task *pb.TaskAssignment) error {
+	done := make(chan error, 1)
+	s.mu.Lock()
+	s.waiters[task.TaskId] = append(s.waiters[task.TaskId], done)
+	s.mu.Unlock()
+
+	if err := s.Push(task); err != nil {
+		s.drop(task.TaskId, done)
+		return err
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		// the task may still be accepted; the client can look it up by ID
+		s.drop(task.TaskId, done)
+		return ctx.Err()
+	}
+}
+
+// Submitted implements scheduler.SubmitObserver
+func (s *GRPCSource) Submitted(taskID string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := s.waiters[taskID]
+	if len(list) == 0 {
+		return // a requeued task, nobody is waiting
+	}
+	list[0] <- err
+	if len(list) == 1 {
+		delete(s.waiters, taskID)
+	} else {
+		s.waiters[taskID] = list[1:]
+	}
+}
+
+// drop removes a waiter that gave up
+func (s *GRPCSource) drop(taskID string, done chan error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := s.waiters[taskID]
+	for i, c := range list {
+		if c == done {
+			list = append(list[:i:i], list[i+1:]...)
+			break
+		}
+	}
+	if len(list) == 0 {
+		delete(s.waiters, taskID)
+	} else {
+		s.waiters[taskID] = list
+	}
+}
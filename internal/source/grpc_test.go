@@ -0,0 +1,77 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// submitAsync runs Submit in the background and waits until the task was
+// handed out, so the Dispatcher's answer has a waiter
+func submitAsync(t *testing.T, s *GRPCSource, ctx context.Context, id string) <-chan error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- s.Submit(ctx, &pb.TaskAssignment{TaskId: id}) }()
+	if task, err := nextWithin(s.MemorySource, time.Second); err != nil || task.TaskId != id {
+		t.Fatalf("Next() = %v, %v; want %s", task, err, id)
+	}
+	return done
+}
+
+// answer waits for the result of a Submit
+func answer(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("Submit did not return")
+		return nil
+	}
+}
+
+func TestGRPCSourceWaiters(t *testing.T) {
+	s := NewGRPCSource()
+	rejected := errors.New("rejected")
+
+	t1 := submitAsync(t, s, context.Background(), "t1")
+	t2 := submitAsync(t, s, context.Background(), "t2")
+	dup := submitAsync(t, s, context.Background(), "t1")
+
+	// answers are matched by task ID, and in submit order for the same ID
+	s.Submitted("t2", rejected)
+	if err := answer(t, t2); !errors.Is(err, rejected) {
+		t.Errorf("Submit(t2) = %v, want %v", err, rejected)
+	}
+	s.Submitted("t1", nil)
+	if err := answer(t, t1); err != nil {
+		t.Errorf("Submit(t1) = %v, want nil", err)
+	}
+	s.Submitted("t1", rejected)
+	if err := answer(t, dup); !errors.Is(err, rejected) {
+		t.Errorf("second Submit(t1) = %v, want %v", err, rejected)
+	}
+
+	// an answer nobody waits for, e.g. for a requeued task, is dropped
+	s.Submitted("t3", nil)
+}
+
+func TestGRPCSourceGiveUp(t *testing.T) {
+	s := NewGRPCSource()
+	ctx, cancel := context.WithCancel(context.Background())
+	gaveUp := submitAsync(t, s, ctx, "t1")
+	waiting := submitAsync(t, s, context.Background(), "t1")
+
+	cancel()
+	if err := answer(t, gaveUp); !errors.Is(err, context.Canceled) {
+		t.Errorf("Submit() = %v, want context.Canceled", err)
+	}
+	// the answer goes to the waiter that is still there
+	s.Submitted("t1", nil)
+	if err := answer(t, waiting); err != nil {
+		t.Errorf("Submit() = %v, want nil", err)
+	}
+}
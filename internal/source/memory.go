@@ -0,0 +1,123 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// MemorySource is an in-process task queue. Tasks are handed out in push
+// order; a Nack with requeue puts the task at the back of the queue again.
+// Once closed, Next returns ErrClosed after the queue is empty and every
+// handed out task was acked or nacked.
+// It is a thread-safe component.
+type MemorySource struct {
+	mu    sync.Mutex
+	queue []*pb.TaskAssignment
+	// outstanding holds the latest handed out copy of every task not acked yet
+	outstanding map[string]*pb.TaskAssignment // key is task_id
+	closed      bool
+	changed     chan struct{} // closed and replaced whenever the state changes
+}
+
+// NewMemorySource constructs an empty MemorySource
+func NewMemorySource() *MemorySource {
+	return &MemorySource{
+		outstanding: make(map[string]*pb.TaskAssignment),
+		changed:     make(chan struct{}),
+	}
+}
+
+// Push appends a task to the queue
+func (s *MemorySource) Push(task *pb.TaskAssignment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+	s.queue = append(s.queue, task)
+	s.broadcast()
+	return nil
+}
+
+// Close stops accepting new tasks. Tasks already queued are still handed out.
+func (s *MemorySource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.broadcast()
+	return nil
+}
+
+// Next blocks until a task is queued
+func (s *MemorySource) Next(ctx context.Context) (*pb.TaskAssignment, error) {
+	for {
+		s.mu.Lock()
+		if len(s.queue) > 0 {
+			task := s.queue[0]
+			s.queue[0] = nil
+			s.queue = s.queue[1:]
+			s.outstanding[task.TaskId] = task
+			s.mu.Unlock()
+			return task, nil
+		}
+		if s.closed && len(s.outstanding) == 0 {
+			s.mu.Unlock()
+			return nil, ErrClosed
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Ack forgets every handed out copy of a task that reached a terminal state
+func (s *MemorySource) Ack(taskID string) error {
+	_, err := s.take(taskID)
+	return err
+}
+
+// Nack hands a task back; with requeue it is queued again. Other handed out
+// copies of the task are duplicates and are dropped.
+func (s *MemorySource) Nack(taskID string, requeue bool) error {
+	task, err := s.take(taskID)
+	if err != nil || !requeue {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// requeued tasks are accepted even after Close, they were handed out before
+	s.queue = append(s.queue, task)
+	s.broadcast()
+	return nil
+}
+
+// take removes a handed out task, together with any earlier copies of it
+func (s *MemorySource) take(taskID string) (*pb.TaskAssignment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.outstanding[taskID]
+	if !ok {
+		return nil, fmt.Errorf("no outstanding task %s", taskID)
+	}
+	delete(s.outstanding, taskID)
+	s.broadcast()
+	return task, nil
+}
+
+// broadcast wakes every blocked Next. Callers must hold s.mu.
+func (s *MemorySource) broadcast() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
@@ -0,0 +1,97 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// nextWithin calls Next with a short deadline
+func nextWithin(s *MemorySource, d time.Duration) (*pb.TaskAssignment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return s.Next(ctx)
+}
+
+func TestMemorySourceClose(t *testing.T) {
+	tests := []struct {
+		name   string
+		settle func(s *MemorySource) error
+	}{
+		{"acked", func(s *MemorySource) error { return s.Ack("t1") }},
+		{"nacked", func(s *MemorySource) error { return s.Nack("t1", false) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemorySource()
+			if err := s.Push(&pb.TaskAssignment{TaskId: "t1"}); err != nil {
+				t.Fatal(err)
+			}
+			s.Close()
+			if err := s.Push(&pb.TaskAssignment{TaskId: "t2"}); !errors.Is(err, ErrClosed) {
+				t.Errorf("Push() after Close = %v, want ErrClosed", err)
+			}
+
+			// tasks queued before Close are still handed out
+			if task, err := nextWithin(s, time.Second); err != nil || task.TaskId != "t1" {
+				t.Fatalf("Next() = %v, %v; want t1", task, err)
+			}
+			// t1 is outstanding, so the source is not done yet
+			if _, err := nextWithin(s, 20*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("Next() with a task outstanding = %v, want to block", err)
+			}
+
+			if err := tt.settle(s); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := nextWithin(s, time.Second); !errors.Is(err, ErrClosed) {
+				t.Errorf("Next() once settled = %v, want ErrClosed", err)
+			}
+		})
+	}
+}
+
+func TestMemorySourceRequeue(t *testing.T) {
+	s := NewMemorySource()
+	s.Push(&pb.TaskAssignment{TaskId: "t1"})
+	s.Close()
+	if _, err := nextWithin(s, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// requeued tasks come back even after Close
+	if err := s.Nack("t1", true); err != nil {
+		t.Fatal(err)
+	}
+	if task, err := nextWithin(s, time.Second); err != nil || task.TaskId != "t1" {
+		t.Fatalf("Next() after a requeue = %v, %v; want t1", task, err)
+	}
+	if err := s.Ack("t1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Ack("t1"); err == nil {
+		t.Error("second Ack succeeded")
+	}
+}
+
+func TestMemorySourceDuplicates(t *testing.T) {
+	s := NewMemorySource()
+	// the same task pushed twice; one Ack settles both copies
+	s.Push(&pb.TaskAssignment{TaskId: "t1"})
+	s.Push(&pb.TaskAssignment{TaskId: "t1"})
+	s.Close()
+	for i := 0; i < 2; i++ {
+		if _, err := nextWithin(s, time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Ack("t1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := nextWithin(s, time.Second); !errors.Is(err, ErrClosed) {
+		t.Errorf("Next() = %v, want ErrClosed", err)
+	}
+}
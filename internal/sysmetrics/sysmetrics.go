@@ -0,0 +1,55 @@
+// Package sysmetrics samples host and cgroup resource usage for the worker's
+// heartbeats. Only Linux is supported; elsewhere Sample returns nil.
+package sysmetrics
+
+import (
+	"sync"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// Collector turns successive /proc samples into NodeMetrics. CPU usage is
+// computed between two calls to Sample, so the first sample reports 0.
+// It is a thread-safe component.
+type Collector struct {
+	mu      sync.Mutex
+	prevCPU cpuTimes
+	last    *pb.NodeMetrics
+}
+
+// cpuTimes are the aggregate jiffies from the "cpu" line of /proc/stat
+type cpuTimes struct {
+	idle, total uint64
+}
+
+// NewCollector constructs a Collector
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// Sample reads fresh metrics. Metrics that cannot be read are left zero; nil
+// is returned when nothing could be read at all (e.g. not on Linux).
+func (c *Collector) Sample() *pb.NodeMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, cpu, ok := readMetrics()
+	if !ok {
+		c.last = nil
+		return nil
+	}
+	if c.prevCPU.total > 0 && cpu.total > c.prevCPU.total {
+		busy := float64((cpu.total - c.prevCPU.total) - (cpu.idle - c.prevCPU.idle))
+		m.CpuUsage = busy / float64(cpu.total-c.prevCPU.total)
+	}
+	c.prevCPU = cpu
+	c.last = m
+	return m
+}
+
+// Last returns the most recent sample without reading /proc again
+func (c *Collector) Last() *pb.NodeMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last
+}
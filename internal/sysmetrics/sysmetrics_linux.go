@@ -0,0 +1,210 @@
+//go:build linux
+
+package sysmetrics
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// procRoot and cgroupRoot are where procfs and cgroupfs are mounted;
+// tests point them at fixtures
+var (
+	procRoot   = "/proc"
+	cgroupRoot = "/sys/fs/cgroup"
+)
+
+// unlimited treats cgroup v1's "no limit" values (close to MaxInt64) as 0
+const unlimited = 1 << 60
+
+func readMetrics() (*pb.NodeMetrics, cpuTimes, bool) {
+	m := &pb.NodeMetrics{NumCpus: int32(runtime.NumCPU())}
+
+	cpu, cpuOK := readProcStat()
+	memOK := readMeminfo(m)
+	loadOK := readLoadavg(m)
+	readCgroup(m)
+
+	return m, cpu, cpuOK || memOK || loadOK
+}
+
+// readProcStat parses the aggregate "cpu" line:
+// cpu user nice system idle iowait irq softirq steal guest guest_nice
+func readProcStat() (cpuTimes, bool) {
+	f, err := os.Open(filepath.Join(procRoot, "stat"))
+	if err != nil {
+		return cpuTimes{}, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		var t cpuTimes
+		// guest and guest_nice are already included in user and nice
+		for i, field := range fields[1:] {
+			if i >= 8 {
+				break
+			}
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return cpuTimes{}, false
+			}
+			t.total += v
+			if i == 3 || i == 4 { // idle, iowait
+				t.idle += v
+			}
+		}
+		return t, true
+	}
+	return cpuTimes{}, false
+}
+
+// readMeminfo fills MemTotal and MemAvailable (reported in kB)
+func readMeminfo(m *pb.NodeMetrics) bool {
+	data, err := os.ReadFile(filepath.Join(procRoot, "meminfo"))
+	if err != nil {
+		return false
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		fields := strings.Fields(string(line))
+		if len(fields) < 2 {
+			continue
+		}
+		v, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			m.MemTotalBytes = v * 1024
+		case "MemAvailable:":
+			m.MemAvailableBytes = v * 1024
+		}
+	}
+	return m.MemTotalBytes > 0
+}
+
+// readLoadavg fills the 1, 5 and 15 minute load averages
+func readLoadavg(m *pb.NodeMetrics) bool {
+	data, err := os.ReadFile(filepath.Join(procRoot, "loadavg"))
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return false
+	}
+	loads := make([]float64, 3)
+	for i := range loads {
+		if loads[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return false
+		}
+	}
+	m.Load1, m.Load5, m.Load15 = loads[0], loads[1], loads[2]
+	return true
+}
+
+// readCgroup fills the CPU and memory limits of the process's cgroup,
+// trying cgroup v2 first and v1 after
+func readCgroup(m *pb.NodeMetrics) {
+	data, err := os.ReadFile(filepath.Join(procRoot, "self", "cgroup"))
+	if err != nil {
+		return
+	}
+	v1 := make(map[string]string) // controller -> path
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			if readCgroupV2(m, cgroupDir(cgroupRoot, parts[2], "cpu.max")) {
+				return
+			}
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			v1[controller] = parts[2]
+		}
+	}
+
+	if path, ok := v1["cpu"]; ok {
+		dir := cgroupDir(filepath.Join(cgroupRoot, "cpu"), path, "cpu.cfs_quota_us")
+		quota, qerr := readInt(filepath.Join(dir, "cpu.cfs_quota_us"))
+		period, perr := readInt(filepath.Join(dir, "cpu.cfs_period_us"))
+		if qerr == nil && perr == nil && quota > 0 && period > 0 {
+			m.CgroupCpuLimitMillis = quota * 1000 / period
+		}
+	}
+	if path, ok := v1["memory"]; ok {
+		dir := cgroupDir(filepath.Join(cgroupRoot, "memory"), path, "memory.limit_in_bytes")
+		if limit, err := readInt(filepath.Join(dir, "memory.limit_in_bytes")); err == nil && limit < unlimited {
+			m.CgroupMemoryLimitBytes = limit
+		}
+		if usage, err := readInt(filepath.Join(dir, "memory.usage_in_bytes")); err == nil {
+			m.CgroupMemoryUsageBytes = usage
+		}
+	}
+}
+
+// readCgroupV2 reads cpu.max, memory.max and memory.current from dir
+func readCgroupV2(m *pb.NodeMetrics, dir string) bool {
+	found := false
+	// cpu.max is "$MAX $PERIOD", $MAX being "max" when unlimited
+	if data, err := os.ReadFile(filepath.Join(dir, "cpu.max")); err == nil {
+		found = true
+		fields := strings.Fields(string(data))
+		if len(fields) == 2 && fields[0] != "max" {
+			quota, qerr := strconv.ParseInt(fields[0], 10, 64)
+			period, perr := strconv.ParseInt(fields[1], 10, 64)
+			if qerr == nil && perr == nil && period > 0 {
+				m.CgroupCpuLimitMillis = quota * 1000 / period
+			}
+		}
+	}
+	if limit, err := readInt(filepath.Join(dir, "memory.max")); err == nil {
+		found = true
+		m.CgroupMemoryLimitBytes = limit
+	}
+	if usage, err := readInt(filepath.Join(dir, "memory.current")); err == nil {
+		found = true
+		m.CgroupMemoryUsageBytes = usage
+	}
+	return found
+}
+
+// cgroupDir maps a cgroup path to its directory under mount. Inside a
+// container with a private cgroup namespace the path may not exist under the
+// mount; then the mount itself is the process's cgroup.
+func cgroupDir(mount, path, probe string) string {
+	dir := filepath.Join(mount, path)
+	if _, err := os.Stat(filepath.Join(dir, probe)); err == nil {
+		return dir
+	}
+	return mount
+}
+
+// readInt reads a file holding a single integer; "max" reads as 0
+func readInt(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	s := strings.TrimSpace(string(data))
+	if s == "max" {
+		return 0, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
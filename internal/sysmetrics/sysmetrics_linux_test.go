@@ -0,0 +1,155 @@
+//go:build linux
+
+package sysmetrics
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// fixture points procRoot and cgroupRoot at a temporary tree holding files,
+// keyed by their path below it
+func fixture(t *testing.T, files map[string]string) {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldProc, oldCgroup := procRoot, cgroupRoot
+	procRoot, cgroupRoot = filepath.Join(root, "proc"), filepath.Join(root, "cgroup")
+	t.Cleanup(func() { procRoot, cgroupRoot = oldProc, oldCgroup })
+}
+
+func TestReadProcStat(t *testing.T) {
+	tests := []struct {
+		name string
+		stat string
+		want cpuTimes
+		ok   bool
+	}{
+		// guest and guest_nice are not added again
+		{"aggregate line", "cpu  10 20 30 400 50 6 7 8 9 10\ncpu0 1 2 3 4 5 6 7 8 9 10\n", cpuTimes{idle: 450, total: 531}, true},
+		{"old kernel", "cpu 10 20 30 400\n", cpuTimes{idle: 400, total: 460}, true},
+		{"garbage", "cpu 10 x 30 400\n", cpuTimes{}, false},
+		{"no cpu line", "intr 1 2 3\n", cpuTimes{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture(t, map[string]string{"proc/stat": tt.stat})
+			got, ok := readProcStat()
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("readProcStat() = %+v, %v; want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestReadMeminfoAndLoadavg(t *testing.T) {
+	fixture(t, map[string]string{
+		"proc/meminfo": "MemTotal:       16384 kB\nMemFree:         1024 kB\nMemAvailable:    8192 kB\nHugePages_Total:       0\n",
+		"proc/loadavg": "0.50 1.25 2.00 1/234 5678\n",
+	})
+	m := &pb.NodeMetrics{}
+	if !readMeminfo(m) || m.MemTotalBytes != 16384*1024 || m.MemAvailableBytes != 8192*1024 {
+		t.Errorf("meminfo: total %d, available %d", m.MemTotalBytes, m.MemAvailableBytes)
+	}
+	if !readLoadavg(m) || m.Load1 != 0.5 || m.Load5 != 1.25 || m.Load15 != 2 {
+		t.Errorf("loadavg: %v %v %v", m.Load1, m.Load5, m.Load15)
+	}
+
+	fixture(t, map[string]string{"proc/loadavg": "0.50\n"})
+	if readMeminfo(m) || readLoadavg(m) {
+		t.Error("missing or short files read as ok")
+	}
+}
+
+func TestReadCgroup(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		cpu   int64 // CgroupCpuLimitMillis
+		limit int64 // CgroupMemoryLimitBytes
+		usage int64 // CgroupMemoryUsageBytes
+	}{
+		{"v2", map[string]string{
+			"proc/self/cgroup":          "0::/app\n",
+			"cgroup/app/cpu.max":        "150000 100000\n",
+			"cgroup/app/memory.max":     "1073741824\n",
+			"cgroup/app/memory.current": "52428800\n",
+		}, 1500, 1 << 30, 50 << 20},
+		{"v2 unlimited", map[string]string{
+			"proc/self/cgroup":          "0::/app\n",
+			"cgroup/app/cpu.max":        "max 100000\n",
+			"cgroup/app/memory.max":     "max\n",
+			"cgroup/app/memory.current": "4096\n",
+		}, 0, 0, 4096},
+		// a private cgroup namespace: the path is not below the mount
+		{"v2 namespaced", map[string]string{
+			"proc/self/cgroup":      "0::/../../app\n",
+			"cgroup/cpu.max":        "50000 100000\n",
+			"cgroup/memory.max":     "2048\n",
+			"cgroup/memory.current": "1024\n",
+		}, 500, 2048, 1024},
+		{"v1", map[string]string{
+			"proc/self/cgroup":                        "12:memory:/app\n11:cpu,cpuacct:/app\n1:name=systemd:/app\n",
+			"cgroup/cpu/app/cpu.cfs_quota_us":         "200000\n",
+			"cgroup/cpu/app/cpu.cfs_period_us":        "100000\n",
+			"cgroup/memory/app/memory.limit_in_bytes": "268435456\n",
+			"cgroup/memory/app/memory.usage_in_bytes": "1048576\n",
+		}, 2000, 256 << 20, 1 << 20},
+		{"v1 unlimited", map[string]string{
+			"proc/self/cgroup":                    "4:memory:/\n3:cpu:/\n",
+			"cgroup/cpu/cpu.cfs_quota_us":         "-1\n",
+			"cgroup/cpu/cpu.cfs_period_us":        "100000\n",
+			"cgroup/memory/memory.limit_in_bytes": "9223372036854771712\n",
+			"cgroup/memory/memory.usage_in_bytes": "1048576\n",
+		}, 0, 0, 1 << 20},
+		{"no cgroup", map[string]string{}, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture(t, tt.files)
+			m := &pb.NodeMetrics{}
+			readCgroup(m)
+			if m.CgroupCpuLimitMillis != tt.cpu || m.CgroupMemoryLimitBytes != tt.limit || m.CgroupMemoryUsageBytes != tt.usage {
+				t.Errorf("cpu %d, limit %d, usage %d; want %d, %d, %d",
+					m.CgroupCpuLimitMillis, m.CgroupMemoryLimitBytes, m.CgroupMemoryUsageBytes, tt.cpu, tt.limit, tt.usage)
+			}
+		})
+	}
+}
+
+func TestCollectorCPUUsage(t *testing.T) {
+	fixture(t, map[string]string{"proc/stat": "cpu 100 0 0 100\n"})
+	c := NewCollector()
+	if m := c.Sample(); m == nil || m.CpuUsage != 0 {
+		t.Fatalf("first sample = %v, want CPU usage 0", m)
+	}
+
+	// 30 busy and 10 idle jiffies since the first sample
+	if err := os.WriteFile(filepath.Join(procRoot, "stat"), []byte("cpu 130 0 0 110\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := c.Sample()
+	if m == nil || math.Abs(m.CpuUsage-0.75) > 1e-9 {
+		t.Fatalf("second sample = %v, want CPU usage 0.75", m)
+	}
+	if c.Last() != m {
+		t.Error("Last() is not the latest sample")
+	}
+
+	fixture(t, map[string]string{})
+	if m := c.Sample(); m != nil || c.Last() != nil {
+		t.Errorf("sample without /proc = %v, want nil", m)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package sysmetrics
+
+import (
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+func readMetrics() (*pb.NodeMetrics, cpuTimes, bool) {
+	return nil, cpuTimes{}, false
+}
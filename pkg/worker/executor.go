@@ -0,0 +1,376 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// HandlerFunc executes one task. It receives the raw task_payload and returns
+// the output bytes that are sent back to the master in the TaskResult.
+// Handlers should return promptly once ctx is cancelled, and wrap errors
+// that a retry cannot fix with Permanent.
+type HandlerFunc func(ctx context.Context, payload []byte) ([]byte, error)
+
+// defaultQueueSize bounds the number of assignments waiting for a free slot
+const defaultQueueSize = 1024
+
+// Causes of a cancelled task context, mapped to the reported TaskStatus
+var (
+	errCancelled = errors.New("cancelled by the master")
+	errTimedOut  = errors.New("timed out")
+)
+
+// Failures that are not the handler's own error, mapped to the ErrorClass
+var (
+	errUnknownTask = errors.New("unknown task name")
+	errPanicked    = errors.New("panicked")
+)
+
+// permanentError marks a handler error that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a handler error as permanent: the master does not retry
+// the task unless its retry policy lists ERROR_CLASS_PERMANENT explicitly
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// errorClass classifies a failed attempt's error
+func errorClass(err error) pb.ErrorClass {
+	var permanent *permanentError
+	switch {
+	case errors.Is(err, errUnknownTask):
+		return pb.ErrorClass_ERROR_CLASS_UNKNOWN_TASK
+	case errors.Is(err, errPanicked):
+		return pb.ErrorClass_ERROR_CLASS_PANIC
+	case errors.As(err, &permanent):
+		return pb.ErrorClass_ERROR_CLASS_PERMANENT
+	default:
+		return pb.ErrorClass_ERROR_CLASS_HANDLER
+	}
+}
+
+// attemptKey identifies one attempt of a task. A revoked attempt may still be
+// winding down when the next attempt of the same task arrives.
+type attemptKey struct {
+	taskID string
+	token  uint64
+}
+
+// attempt is a queued or running attempt of a task
+type attempt struct {
+	task *pb.TaskAssignment
+	// token is echoed in TaskStarted and TaskResult; it changes when a
+	// redelivery of the task takes the attempt over (see handover)
+	token uint64
+	stop  context.CancelCauseFunc // set once it runs
+	cause error                   // set once it was cancelled
+}
+
+// executor runs task assignments on a fixed pool of goroutines.
+// The pool size is the worker's MaxConcurrency, so at most that many handlers
+// run at the same time; further assignments wait in the queue.
+type executor struct {
+	mu       sync.RWMutex
+	handlers map[string]HandlerFunc // key is task_name
+
+	queue   chan *attempt
+	started func(*pb.TaskStarted) // called when a task leaves the queue
+	report  func(*pb.TaskResult)  // called once per finished task
+	// killGrace is how long a handler may keep running after its context
+	// was cancelled before it is abandoned
+	killGrace time.Duration
+
+	// in-flight accounting reported in every StatusUpdate
+	active   atomic.Int32 // handlers currently running
+	queued   atomic.Int32 // accepted but waiting for a free slot
+	onChange func()       // called after every start/finish
+
+	latency *latencyTracker // execution time of recent tasks
+
+	// cancellation by attempt: running attempts are stopped through their
+	// context, queued ones are skipped when they reach a slot
+	cancelMu sync.Mutex
+	attempts map[attemptKey]*attempt
+}
+
+func newExecutor(queueSize int, killGrace time.Duration, started func(*pb.TaskStarted), report func(*pb.TaskResult), onChange func()) *executor {
+	return &executor{
+		handlers:  make(map[string]HandlerFunc),
+		queue:     make(chan *attempt, queueSize),
+		killGrace: killGrace,
+		started:   started,
+		report:    report,
+		onChange:  onChange,
+		latency:   newLatencyTracker(),
+
+		attempts: make(map[attemptKey]*attempt),
+	}
+}
+
+// counts returns (active, queued)
+func (e *executor) counts() (int32, int32) {
+	return e.active.Load(), e.queued.Load()
+}
+
+// register adds or replaces the handler for a task name
+func (e *executor) register(name string, fn HandlerFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handlers[name] = fn
+}
+
+func (e *executor) handler(name string) (HandlerFunc, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	fn, ok := e.handlers[name]
+	return fn, ok
+}
+
+// start launches n pool goroutines; they exit when ctx is cancelled
+func (e *executor) start(ctx context.Context, n int) {
+	for i := 0; i < n; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case a := <-e.queue:
+					e.run(ctx, a)
+				}
+			}
+		}()
+	}
+}
+
+// submit queues an assignment without blocking the caller (the receive loop).
+// If the queue is full the task is reported as failed right away.
+func (e *executor) submit(task *pb.TaskAssignment) {
+	a := &attempt{task: task, token: task.FencingToken}
+	e.cancelMu.Lock()
+	e.attempts[attemptKey{task.TaskId, a.token}] = a
+	e.cancelMu.Unlock()
+
+	// count before enqueueing so a pool goroutine never sees it negative
+	e.queued.Add(1)
+	select {
+	case e.queue <- a:
+	default:
+		e.queued.Add(-1)
+		e.finish(a)
+		now := time.Now().UnixNano()
+		e.report(&pb.TaskResult{
+			TaskId:            task.TaskId,
+			Status:            pb.TaskStatus_TASK_STATUS_FAILED,
+			Error:             "worker queue is full",
+			ErrorClass:        pb.ErrorClass_ERROR_CLASS_REJECTED,
+			StartTimeUnixNano: now,
+			EndTimeUnixNano:   now,
+			FencingToken:      task.FencingToken,
+		})
+	}
+}
+
+// cancel stops a running attempt through its context, or marks a queued one
+// so it is reported as cancelled instead of being run. It reports false if
+// the attempt is neither queued nor running.
+func (e *executor) cancel(taskID string, token uint64, reason string) bool {
+	e.cancelMu.Lock()
+	defer e.cancelMu.Unlock()
+
+	a, ok := e.attempts[attemptKey{taskID, token}]
+	if !ok {
+		return false
+	}
+	if a.cause == nil {
+		a.cause = fmt.Errorf("%w: %s", errCancelled, reason)
+	}
+	if a.stop != nil {
+		a.stop(a.cause)
+	}
+	return true
+}
+
+// handover lets a redelivered assignment with a new token take over the
+// attempt of the task queued or running under the old token. It reports
+// false if there is no such attempt, or it was cancelled or has finished;
+// the redelivery then has to run on its own.
+func (e *executor) handover(taskID string, from, to uint64) bool {
+	e.cancelMu.Lock()
+	defer e.cancelMu.Unlock()
+
+	a, ok := e.attempts[attemptKey{taskID, from}]
+	if !ok || a.cause != nil {
+		return false
+	}
+	delete(e.attempts, attemptKey{taskID, from})
+	a.token = to
+	e.attempts[attemptKey{taskID, to}] = a
+	return true
+}
+
+// begin registers an attempt's cancel function before it runs and returns
+// its current token; it returns the cancellation cause instead if the
+// attempt was cancelled while queued
+func (e *executor) begin(a *attempt, stop context.CancelCauseFunc) (uint64, error) {
+	e.cancelMu.Lock()
+	defer e.cancelMu.Unlock()
+
+	if a.cause != nil {
+		return a.token, a.cause
+	}
+	a.stop = stop
+	return a.token, nil
+}
+
+// finish forgets an attempt and returns the token its result carries; it
+// can no longer be cancelled or taken over
+func (e *executor) finish(a *attempt) uint64 {
+	e.cancelMu.Lock()
+	defer e.cancelMu.Unlock()
+
+	key := attemptKey{a.task.TaskId, a.token}
+	if e.attempts[key] == a {
+		delete(e.attempts, key)
+	}
+	return a.token
+}
+
+// run executes one attempt and reports its TaskResult
+func (e *executor) run(ctx context.Context, a *attempt) {
+	e.queued.Add(-1)
+
+	task := a.task
+	ctx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+	token, cause := e.begin(a, stop)
+	if cause != nil {
+		e.finish(a)
+		now := time.Now().UnixNano()
+		log.Printf("Task %s (%s) cancelled before it started", task.TaskId, task.TaskName)
+		e.report(&pb.TaskResult{
+			TaskId:            task.TaskId,
+			Status:            pb.TaskStatus_TASK_STATUS_CANCELLED,
+			Error:             cause.Error(),
+			StartTimeUnixNano: now,
+			EndTimeUnixNano:   now,
+			FencingToken:      token,
+		})
+		e.onChange()
+		return
+	}
+
+	if task.TimeoutMillis > 0 {
+		// counted from here, so time spent in the queue does not count
+		timeout := time.Duration(task.TimeoutMillis) * time.Millisecond
+		var stopTimer context.CancelFunc
+		ctx, stopTimer = context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w after %v", errTimedOut, timeout))
+		defer stopTimer()
+	}
+
+	e.active.Add(1)
+	e.onChange()
+
+	start := time.Now()
+	e.started(&pb.TaskStarted{
+		TaskId:            task.TaskId,
+		StartTimeUnixNano: start.UnixNano(),
+		FencingToken:      token,
+	})
+	output, err := e.execute(ctx, task)
+	end := time.Now()
+	token = e.finish(a)
+
+	result := &pb.TaskResult{
+		TaskId:            task.TaskId,
+		Status:            pb.TaskStatus_TASK_STATUS_SUCCEEDED,
+		Output:            output,
+		StartTimeUnixNano: start.UnixNano(),
+		EndTimeUnixNano:   end.UnixNano(),
+		FencingToken:      token,
+	}
+	if err != nil {
+		result.Status = pb.TaskStatus_TASK_STATUS_FAILED
+		result.Error = err.Error()
+		result.ErrorClass = errorClass(err)
+		switch cause := context.Cause(ctx); {
+		case errors.Is(cause, errCancelled):
+			result.Status = pb.TaskStatus_TASK_STATUS_CANCELLED
+			result.Error = cause.Error()
+			result.ErrorClass = pb.ErrorClass_ERROR_CLASS_UNSPECIFIED
+		case errors.Is(cause, errTimedOut):
+			result.Status = pb.TaskStatus_TASK_STATUS_TIMED_OUT
+			result.Error = cause.Error()
+			result.ErrorClass = pb.ErrorClass_ERROR_CLASS_TIMEOUT
+		}
+	}
+	log.Printf("Task %s (%s) finished: %s in %v", task.TaskId, task.TaskName, result.Status, end.Sub(start))
+	if !errors.Is(err, errUnknownTask) {
+		// nothing ran, and a name per bad assignment would grow the tracker
+		e.latency.observe(task.TaskName, end.Sub(start))
+	}
+	e.report(result)
+
+	e.active.Add(-1)
+	e.onChange()
+}
+
+// execute runs the handler until it returns or ctx is done. Go cannot stop a
+// goroutine, so a handler that ignores its cancelled context is abandoned
+// after killGrace: its slot is freed and the task reported, while the
+// goroutine runs on until it returns by itself.
+func (e *executor) execute(ctx context.Context, task *pb.TaskAssignment) ([]byte, error) {
+	type outcome struct {
+		output []byte
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		output, err := e.invoke(ctx, task)
+		done <- outcome{output, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.output, o.err
+	case <-ctx.Done():
+	}
+	select {
+	case o := <-done:
+		return o.output, o.err
+	case <-time.After(e.killGrace):
+		log.Printf("Task %s (%s) did not return within %v of being stopped; abandoning it", task.TaskId, task.TaskName, e.killGrace)
+		return nil, fmt.Errorf("handler did not return within %v of being stopped", e.killGrace)
+	}
+}
+
+// invoke looks up the handler and calls it, turning unknown task names and
+// handler panics into errors
+func (e *executor) invoke(ctx context.Context, task *pb.TaskAssignment) (output []byte, err error) {
+	fn, ok := e.handler(task.TaskName)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", errUnknownTask, task.TaskName)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			output, err = nil, fmt.Errorf("handler for %q %w: %v", task.TaskName, errPanicked, r)
+		}
+	}()
+	return fn(ctx, task.TaskPayload)
+}
@@ -0,0 +1,291 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// newTestExecutor starts an executor with slots pool goroutines whose
+// results are delivered on the returned channel
+func newTestExecutor(t *testing.T, slots, queueSize int, killGrace time.Duration) (*executor, chan *pb.TaskResult) {
+	t.Helper()
+	results := make(chan *pb.TaskResult, 16)
+	e := newExecutor(queueSize, killGrace, func(*pb.TaskStarted) {}, func(r *pb.TaskResult) { results <- r }, func() {})
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	e.start(ctx, slots)
+	return e, results
+}
+
+// waitResult returns the next reported result
+func waitResult(t *testing.T, results <-chan *pb.TaskResult) *pb.TaskResult {
+	t.Helper()
+	select {
+	case r := <-results:
+		return r
+	case <-time.After(2 * time.Second):
+		t.Fatal("no result reported")
+		return nil
+	}
+}
+
+// blockingHandler runs until its context is done and signals every start
+func blockingHandler(running chan<- struct{}) HandlerFunc {
+	return func(ctx context.Context, payload []byte) ([]byte, error) {
+		running <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+}
+
+func TestExecutorCancel(t *testing.T) {
+	e, results := newTestExecutor(t, 1, 4, time.Second)
+	running := make(chan struct{}, 4)
+	e.register("block", blockingHandler(running))
+
+	e.submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "block", FencingToken: 1})
+	<-running
+	// t2 waits for the only slot
+	e.submit(&pb.TaskAssignment{TaskId: "t2", TaskName: "block", FencingToken: 1})
+
+	if e.cancel("t1", 2, "stale") {
+		t.Error("cancel of an attempt with another token succeeded")
+	}
+	if !e.cancel("t2", 1, "queued") || !e.cancel("t1", 1, "running") {
+		t.Fatal("cancel of a held attempt failed")
+	}
+
+	for _, want := range []struct {
+		id, error string
+	}{
+		{"t1", "cancelled by the master: running"},
+		{"t2", "cancelled by the master: queued"},
+	} {
+		r := waitResult(t, results)
+		if r.TaskId != want.id || r.Status != pb.TaskStatus_TASK_STATUS_CANCELLED || r.Error != want.error {
+			t.Errorf("result = %s %s %q, want %s CANCELLED %q", r.TaskId, r.Status, r.Error, want.id, want.error)
+		}
+	}
+	if e.cancel("t1", 1, "again") {
+		t.Error("cancel of a finished attempt succeeded")
+	}
+}
+
+func TestExecutorTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler HandlerFunc
+		error   string
+	}{
+		{"handler stops", func(ctx context.Context, payload []byte) ([]byte, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}, "timed out after 20ms"},
+		// ignores its context: abandoned after the kill grace
+		{"handler hangs", func(ctx context.Context, payload []byte) ([]byte, error) {
+			time.Sleep(time.Second)
+			return nil, nil
+		}, "timed out after 20ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, results := newTestExecutor(t, 1, 4, 50*time.Millisecond)
+			e.register("slow", tt.handler)
+
+			start := time.Now()
+			e.submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "slow", FencingToken: 1, TimeoutMillis: 20})
+			r := waitResult(t, results)
+			if r.Status != pb.TaskStatus_TASK_STATUS_TIMED_OUT || r.ErrorClass != pb.ErrorClass_ERROR_CLASS_TIMEOUT || r.Error != tt.error {
+				t.Errorf("result = %s %s %q, want TIMED_OUT TIMEOUT %q", r.Status, r.ErrorClass, r.Error, tt.error)
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("result reported after %v, want it within the kill grace", elapsed)
+			}
+			if active, _ := e.counts(); active != 0 {
+				t.Errorf("active = %d after the result, want the slot freed", active)
+			}
+		})
+	}
+}
+
+func TestExecutorKillGrace(t *testing.T) {
+	e, results := newTestExecutor(t, 1, 4, 20*time.Millisecond)
+	running := make(chan struct{}, 1)
+	e.register("stuck", func(ctx context.Context, payload []byte) ([]byte, error) {
+		running <- struct{}{}
+		time.Sleep(time.Second)
+		return nil, nil
+	})
+	e.register("echo", func(ctx context.Context, payload []byte) ([]byte, error) {
+		return payload, nil
+	})
+
+	e.submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "stuck", FencingToken: 1})
+	e.submit(&pb.TaskAssignment{TaskId: "t2", TaskName: "echo", FencingToken: 1, TaskPayload: []byte("ok")})
+	<-running
+	e.cancel("t1", 1, "stop")
+
+	r := waitResult(t, results)
+	if r.TaskId != "t1" || r.Status != pb.TaskStatus_TASK_STATUS_CANCELLED {
+		t.Errorf("result = %s %s, want t1 CANCELLED", r.TaskId, r.Status)
+	}
+	// the abandoned handler no longer holds the only slot
+	r = waitResult(t, results)
+	if r.TaskId != "t2" || r.Status != pb.TaskStatus_TASK_STATUS_SUCCEEDED || string(r.Output) != "ok" {
+		t.Errorf("result = %s %s %q, want t2 SUCCEEDED \"ok\"", r.TaskId, r.Status, r.Output)
+	}
+}
+
+func TestExecutorQueueFull(t *testing.T) {
+	e, results := newTestExecutor(t, 1, 1, time.Second)
+	running := make(chan struct{}, 1)
+	e.register("block", blockingHandler(running))
+
+	e.submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "block", FencingToken: 1})
+	<-running
+	e.submit(&pb.TaskAssignment{TaskId: "t2", TaskName: "block", FencingToken: 1})
+	e.submit(&pb.TaskAssignment{TaskId: "t3", TaskName: "block", FencingToken: 1})
+
+	r := waitResult(t, results)
+	if r.TaskId != "t3" || r.Status != pb.TaskStatus_TASK_STATUS_FAILED || r.ErrorClass != pb.ErrorClass_ERROR_CLASS_REJECTED {
+		t.Errorf("result = %s %s %s, want t3 FAILED REJECTED", r.TaskId, r.Status, r.ErrorClass)
+	}
+	e.cancel("t1", 1, "done")
+	e.cancel("t2", 1, "done")
+}
+
+func TestExecutorErrorClass(t *testing.T) {
+	tests := []struct {
+		name     string
+		taskName string
+		status   pb.TaskStatus
+		class    pb.ErrorClass
+	}{
+		{"succeeded", "ok", pb.TaskStatus_TASK_STATUS_SUCCEEDED, pb.ErrorClass_ERROR_CLASS_UNSPECIFIED},
+		{"handler error", "fail", pb.TaskStatus_TASK_STATUS_FAILED, pb.ErrorClass_ERROR_CLASS_HANDLER},
+		{"permanent error", "bad input", pb.TaskStatus_TASK_STATUS_FAILED, pb.ErrorClass_ERROR_CLASS_PERMANENT},
+		{"panic", "panic", pb.TaskStatus_TASK_STATUS_FAILED, pb.ErrorClass_ERROR_CLASS_PANIC},
+		{"no handler", "resize", pb.TaskStatus_TASK_STATUS_FAILED, pb.ErrorClass_ERROR_CLASS_UNKNOWN_TASK},
+	}
+	e, results := newTestExecutor(t, 1, 4, time.Second)
+	e.register("ok", func(ctx context.Context, payload []byte) ([]byte, error) { return nil, nil })
+	e.register("fail", func(ctx context.Context, payload []byte) ([]byte, error) { return nil, errors.New("boom") })
+	e.register("bad input", func(ctx context.Context, payload []byte) ([]byte, error) {
+		return nil, Permanent(errors.New("cannot parse"))
+	})
+	e.register("panic", func(ctx context.Context, payload []byte) ([]byte, error) { panic("boom") })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e.submit(&pb.TaskAssignment{TaskId: "t1", TaskName: tt.taskName, FencingToken: 1})
+			r := waitResult(t, results)
+			if r.Status != tt.status || r.ErrorClass != tt.class {
+				t.Errorf("result = %s %s (%q), want %s %s", r.Status, r.ErrorClass, r.Error, tt.status, tt.class)
+			}
+		})
+	}
+}
+
+func TestExecutorMaxConcurrency(t *testing.T) {
+	const slots, tasks = 3, 6
+	e, results := newTestExecutor(t, slots, tasks, time.Second)
+	var running, peak atomic.Int32
+	started := make(chan struct{}, tasks)
+	release := make(chan struct{})
+	e.register("work", func(ctx context.Context, payload []byte) ([]byte, error) {
+		n := running.Add(1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		started <- struct{}{}
+		<-release
+		running.Add(-1)
+		return nil, nil
+	})
+
+	for i := range tasks {
+		e.submit(&pb.TaskAssignment{TaskId: fmt.Sprintf("t%d", i), TaskName: "work", FencingToken: 1})
+	}
+	for range slots {
+		<-started
+	}
+	select {
+	case <-started:
+		t.Errorf("a handler started with all %d slots busy", slots)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	for range tasks {
+		waitResult(t, results)
+	}
+	if p := peak.Load(); p != slots {
+		t.Errorf("%d handlers ran at once, want %d", p, slots)
+	}
+}
+
+// waitCounts waits until counts() reports active and queued
+func waitCounts(t *testing.T, e *executor, active, queued int32) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		a, q := e.counts()
+		if a == active && q == queued {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("counts() = %d active %d queued, want %d, %d", a, q, active, queued)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestExecutorCounts(t *testing.T) {
+	e, results := newTestExecutor(t, 1, 2, time.Second)
+	running := make(chan struct{}, 4)
+	release := make(chan struct{})
+	e.register("work", func(ctx context.Context, payload []byte) ([]byte, error) {
+		running <- struct{}{}
+		select {
+		case <-release:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+	waitCounts(t, e, 0, 0)
+
+	e.submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "work", FencingToken: 1})
+	<-running
+	waitCounts(t, e, 1, 0)
+	e.submit(&pb.TaskAssignment{TaskId: "t2", TaskName: "work", FencingToken: 1})
+	e.submit(&pb.TaskAssignment{TaskId: "t3", TaskName: "work", FencingToken: 1})
+	waitCounts(t, e, 1, 2)
+
+	// a rejected assignment is never counted
+	e.submit(&pb.TaskAssignment{TaskId: "t4", TaskName: "work", FencingToken: 1})
+	if r := waitResult(t, results); r.TaskId != "t4" || r.ErrorClass != pb.ErrorClass_ERROR_CLASS_REJECTED {
+		t.Fatalf("result = %s %s, want t4 rejected", r.TaskId, r.ErrorClass)
+	}
+	waitCounts(t, e, 1, 2)
+
+	// a cancelled assignment stays queued until a slot skips it
+	e.cancel("t3", 1, "not needed")
+	release <- struct{}{}
+	waitResult(t, results)
+	<-running
+	waitCounts(t, e, 1, 1)
+
+	release <- struct{}{}
+	if r := waitResult(t, results); r.TaskId != "t2" {
+		t.Fatalf("result for %s, want t2", r.TaskId)
+	}
+	if r := waitResult(t, results); r.TaskId != "t3" || r.Status != pb.TaskStatus_TASK_STATUS_CANCELLED {
+		t.Fatalf("result = %s %s, want t3 cancelled", r.TaskId, r.Status)
+	}
+	waitCounts(t, e, 0, 0)
+}
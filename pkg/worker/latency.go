@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/YilinZhang0101/SwiftScheduler/internal/ring"
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// latencyWindow is the number of recent executions percentiles are based on,
+// overall and per task name
+const latencyWindow = 256
+
+// maxLatencyNames bounds the task names with their own window; observing a
+// new name beyond it drops the one observed least recently
+const maxLatencyNames = 64
+
+// latencyTracker keeps the execution time of recent tasks.
+// It is a thread-safe component.
+type latencyTracker struct {
+	mu     sync.Mutex
+	all    *ring.Ring[time.Duration]
+	byName map[string]*nameWindow
+	seq    uint64 // observations so far, orders the names by recency
+}
+
+// nameWindow is the window of one task name
+type nameWindow struct {
+	r    *ring.Ring[time.Duration]
+	last uint64 // seq of its latest observation
+}
+
+func newLatencyTracker() *latencyTracker {
+	return &latencyTracker{
+		all:    ring.New[time.Duration](latencyWindow),
+		byName: make(map[string]*nameWindow),
+	}
+}
+
+// observe records one execution
+func (l *latencyTracker) observe(name string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.all.Add(d)
+	l.seq++
+	w, ok := l.byName[name]
+	if !ok {
+		if len(l.byName) >= maxLatencyNames {
+			l.evict()
+		}
+		w = &nameWindow{r: ring.New[time.Duration](latencyWindow)}
+		l.byName[name] = w
+	}
+	w.r.Add(d)
+	w.last = l.seq
+}
+
+// evict drops the window of the name observed least recently
+func (l *latencyTracker) evict() {
+	var oldest string
+	last := l.seq + 1
+	for name, w := range l.byName {
+		if w.last < last {
+			oldest, last = name, w.last
+		}
+	}
+	delete(l.byName, oldest)
+}
+
+// stats returns the percentiles over all tasks and per task name
+func (l *latencyTracker) stats() (*pb.LatencyStats, map[string]*pb.LatencyStats) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.all.Len() == 0 {
+		return nil, nil
+	}
+	byName := make(map[string]*pb.LatencyStats, len(l.byName))
+	for name, w := range l.byName {
+		byName[name] = latencyStats(w.r)
+	}
+	return latencyStats(l.all), byName
+}
+
+// latencyStats computes nearest-rank percentiles over a window
+func latencyStats(r *ring.Ring[time.Duration]) *pb.LatencyStats {
+	sorted := r.Values()
+	slices.Sort(sorted)
+	rank := func(p float64) time.Duration {
+		i := int(p*float64(len(sorted))+0.5) - 1
+		return sorted[max(0, min(i, len(sorted)-1))]
+	}
+	return &pb.LatencyStats{
+		P50Nanos: int64(rank(0.50)),
+		P99Nanos: int64(rank(0.99)),
+		Samples:  int32(len(sorted)),
+	}
+}
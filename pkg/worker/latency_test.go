@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+func TestLatencyTrackerEvicts(t *testing.T) {
+	l := newLatencyTracker()
+	l.observe("keep", time.Millisecond)
+	for i := range maxLatencyNames {
+		l.observe(fmt.Sprintf("name-%d", i), time.Millisecond)
+		// "keep" stays the most recent name but one
+		l.observe("keep", time.Millisecond)
+	}
+
+	_, byName := l.stats()
+	if len(byName) != maxLatencyNames {
+		t.Fatalf("%d task names tracked, want %d", len(byName), maxLatencyNames)
+	}
+	if _, ok := byName["name-0"]; ok {
+		t.Error("the name observed least recently was kept")
+	}
+	if s := byName["keep"]; s == nil || s.Samples != maxLatencyNames+1 {
+		t.Errorf("keep = %v, want %d samples", s, maxLatencyNames+1)
+	}
+}
+
+func TestExecutorSkipsUnknownLatency(t *testing.T) {
+	e, results := newTestExecutor(t, 1, 4, time.Second)
+	e.register("ok", func(ctx context.Context, payload []byte) ([]byte, error) { return nil, nil })
+
+	e.submit(&pb.TaskAssignment{TaskId: "t1", TaskName: "missing", FencingToken: 1})
+	e.submit(&pb.TaskAssignment{TaskId: "t2", TaskName: "ok", FencingToken: 1})
+	waitResult(t, results)
+	waitResult(t, results)
+
+	all, byName := e.latency.stats()
+	if all == nil || all.Samples != 1 || len(byName) != 1 || byName["ok"] == nil {
+		t.Errorf("stats() = %v, %v; want one sample for ok only", all, byName)
+	}
+}
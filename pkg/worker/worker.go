@@ -0,0 +1,743 @@
+// Package worker implements the SwiftScheduler worker side so it can be
+// embedded in other binaries: register handlers by task name, then Run.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/YilinZhang0101/SwiftScheduler/internal/keepalive"
+	"github.com/YilinZhang0101/SwiftScheduler/internal/sysmetrics"
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	gk "google.golang.org/grpc/keepalive"
+)
+
+// finishedHistory is how many reported attempts are remembered to detect
+// redelivered assignments
+const finishedHistory = 1024
+
+// Config holds the worker settings. Zero values fall back to defaults.
+type Config struct {
+	MasterAddr        string        // default "localhost:50051"
+	WorkerID          string        // default "<hostname>-<pid>"
+	MaxConcurrency    int32         // size of the execution pool, default 10
+	QueueSize         int           // assignments waiting for a slot, default 1024
+	HeartbeatInterval time.Duration // StatusUpdate period, default 5s
+	// Reconnect backoff after a lost connection: the delay doubles from
+	// ReconnectBaseDelay up to ReconnectMaxDelay, each wait randomized
+	// between half and the full delay
+	ReconnectBaseDelay time.Duration // default 500ms
+	ReconnectMaxDelay  time.Duration // default 30s
+	// DrainTimeout bounds how long Drain waits for in-flight tasks before
+	// deregistering anyway
+	DrainTimeout time.Duration // default 30s
+	// KillGrace is how long a handler may run on after its task timed out
+	// or was cancelled; then its slot is freed and the handler abandoned
+	KillGrace time.Duration // default 5s
+	// Labels are matched against task placements by the master,
+	// e.g. {"zone": "us-east", "pool": "batch"}
+	Labels map[string]string
+
+	// Resources advertised to the master, which reserves task requests
+	// against them
+	CPUMillis   int64            // default runtime.NumCPU() cores
+	MemoryBytes int64            // 0 = not advertised, memory is not enforced
+	Resources   map[string]int64 // named resources, e.g. {"gpu": 2}
+
+	// Transport-level keepalive; zero values use the keepalive package base values
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+}
+
+func (c *Config) setDefaults() {
+	if c.MasterAddr == "" {
+		c.MasterAddr = "localhost:50051"
+	}
+	if c.WorkerID == "" {
+		hostname, _ := os.Hostname()
+		// add PID to prevent multiple instances of the same worker on the same machine
+		c.WorkerID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	if c.MaxConcurrency <= 0 {
+		c.MaxConcurrency = 10
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = defaultQueueSize
+	}
+	if c.CPUMillis <= 0 {
+		c.CPUMillis = int64(runtime.NumCPU()) * 1000
+	}
+	if c.HeartbeatInterval <= 0 {
+		c.HeartbeatInterval = 5 * time.Second
+	}
+	if c.ReconnectBaseDelay <= 0 {
+		c.ReconnectBaseDelay = 500 * time.Millisecond
+	}
+	if c.ReconnectMaxDelay < c.ReconnectBaseDelay {
+		c.ReconnectMaxDelay = max(30*time.Second, c.ReconnectBaseDelay)
+	}
+	if c.DrainTimeout <= 0 {
+		c.DrainTimeout = 30 * time.Second
+	}
+	if c.KillGrace <= 0 {
+		c.KillGrace = 5 * time.Second
+	}
+	base := keepalive.NewController(keepalive.DefaultConfig()).ClientParameters()
+	if c.KeepaliveTime <= 0 {
+		c.KeepaliveTime = base.Time
+	}
+	if c.KeepaliveTimeout <= 0 {
+		c.KeepaliveTimeout = base.Timeout
+	}
+}
+
+// Worker connects to the master, receives task assignments and executes them
+// with the registered handlers.
+type Worker struct {
+	cfg  Config
+	exec *executor
+
+	// gRPC streams do not allow concurrent Send calls; the heartbeat loop and
+	// the execution pool both write to the stream. sendMu also guards the
+	// task bookkeeping that survives reconnects.
+	sendMu sync.Mutex
+	stream pb.SchedulerService_ConnectClient // nil while disconnected
+	// tasks holds every assignment from receipt until its result was sent;
+	// it is re-reported to the master on registration
+	tasks map[string]*pb.InFlightTask // key is task_id
+	// outbox holds results that could not be sent while disconnected
+	outbox []*pb.TaskResult
+	// finished remembers the attempts most recently reported, so a
+	// redelivered assignment is not run twice; finishedOrder is oldest first
+	finished      map[string]uint64 // key is task_id, value the fencing token
+	finishedOrder []string
+
+	// statusNow asks the heartbeat loop for an immediate StatusUpdate
+	statusNow chan struct{}
+	// metrics samples /proc and the cgroup for heartbeats
+	metrics *sysmetrics.Collector
+
+	// draining is set once Drain starts; registrations then ask the master
+	// not to assign new tasks. drained is closed when Drain is done.
+	draining  atomic.Bool
+	drainOnce sync.Once
+	drained   chan struct{}
+}
+
+// New constructs a Worker
+func New(cfg Config) *Worker {
+	cfg.setDefaults()
+	w := &Worker{
+		cfg:       cfg,
+		statusNow: make(chan struct{}, 1),
+		metrics:   sysmetrics.NewCollector(),
+		tasks:     make(map[string]*pb.InFlightTask),
+		finished:  make(map[string]uint64),
+		drained:   make(chan struct{}),
+	}
+	w.exec = newExecutor(cfg.QueueSize, cfg.KillGrace, w.reportStarted, w.reportResult, w.requestStatus)
+	return w
+}
+
+// ID returns the worker ID used when registering with the master
+func (w *Worker) ID() string {
+	return w.cfg.WorkerID
+}
+
+// RegisterHandler binds a task_name to a handler. Assignments whose task_name
+// has no handler are reported to the master as failed.
+func (w *Worker) RegisterHandler(name string, fn HandlerFunc) {
+	w.exec.register(name, fn)
+}
+
+// Run connects to the master and serves task assignments until ctx is
+// cancelled or the worker has drained, in which case it returns nil.
+// A lost connection does not stop running tasks: Run reconnects with
+// jittered exponential backoff, registers again under the same worker ID,
+// re-reports the tasks it holds and delivers the results it could not send
+// in the meantime.
+func (w *Worker) Run(ctx context.Context) error {
+	log.Printf("Attempting to connect to master at %s", w.cfg.MasterAddr)
+
+	// a finished drain stops everything below like a cancelled ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-w.drained:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// 1. [Connect] Create a connection to the gRPC server
+	// We use insecure.NewCredentials() to skip TLS (local development)
+	// Keepalive pings let both sides notice a dead TCP connection quickly.
+	// The ClientConn redials by itself; each session opens a new stream on it.
+	conn, err := grpc.Dial(w.cfg.MasterAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithKeepaliveParams(gk.ClientParameters{
+			Time:                w.cfg.KeepaliveTime,
+			Timeout:             w.cfg.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to master: %w", err)
+	}
+	defer conn.Close()
+
+	// 2. [Create client]
+	client := pb.NewSchedulerServiceClient(conn)
+
+	// 3. [Start execution pool] bounded by MaxConcurrency; it lives as long as
+	// Run so tasks keep running across reconnects
+	w.exec.start(ctx, int(w.cfg.MaxConcurrency))
+
+	// 4. [Supervise] one session per connection
+	delay := w.cfg.ReconnectBaseDelay
+	for {
+		registered, err := w.session(ctx, client)
+		if ctx.Err() != nil {
+			return w.stopErr(ctx)
+		}
+		if registered {
+			// the connection worked; start over with short delays
+			delay = w.cfg.ReconnectBaseDelay
+		}
+
+		// full delay randomized down to half, so workers that lost the same
+		// master do not all come back at the same instant
+		wait := delay/2 + rand.N(delay/2+1)
+		log.Printf("Connection to master lost: %v. Reconnecting in %v", err, wait.Round(time.Millisecond))
+		select {
+		case <-ctx.Done():
+			return w.stopErr(ctx)
+		case <-time.After(wait):
+		}
+		delay = min(delay*2, w.cfg.ReconnectMaxDelay)
+	}
+}
+
+// stopErr is Run's result once ctx is done: nil after a drain
+func (w *Worker) stopErr(ctx context.Context) error {
+	select {
+	case <-w.drained:
+		return nil
+	default:
+		return ctx.Err()
+	}
+}
+
+// Drain shuts the worker down gracefully: it tells the master to stop
+// assigning tasks, waits up to timeout (0 = Config.DrainTimeout) for the
+// tasks it holds to finish and their results to be sent, deregisters and
+// makes Run return nil. Tasks still unfinished at the deadline are handed
+// back to the master and cancelled. Drain blocks until it is done; further
+// calls wait for the first one.
+func (w *Worker) Drain(reason string, timeout time.Duration) {
+	w.drainOnce.Do(func() {
+		if timeout <= 0 {
+			timeout = w.cfg.DrainTimeout
+		}
+		w.draining.Store(true)
+		log.Printf("Draining worker %s (%s), waiting up to %v for in-flight tasks", w.cfg.WorkerID, reason, timeout)
+
+		// 1. [Stop assignments] a later registration carries the flag as well
+		err := w.send(&pb.WorkerMessage{
+			WorkerId: w.cfg.WorkerID,
+			Payload: &pb.WorkerMessage_DrainRequest{
+				DrainRequest: &pb.DrainRequest{Reason: reason},
+			},
+		})
+		if err != nil {
+			log.Printf("Failed to send drain request: %v", err)
+		}
+
+		// 2. [Wait] until every held task has reported its result
+		deadline := time.Now().Add(timeout)
+		unfinished := w.unfinished()
+		for len(unfinished) > 0 && time.Now().Before(deadline) {
+			time.Sleep(100 * time.Millisecond)
+			unfinished = w.unfinished()
+		}
+
+		// 3. [Deregister] the master requeues whatever is left right away
+		err = w.send(&pb.WorkerMessage{
+			WorkerId: w.cfg.WorkerID,
+			Payload: &pb.WorkerMessage_Deregister{
+				Deregister: &pb.Deregister{
+					Reason:            reason,
+					UnfinishedTaskIds: unfinished,
+				},
+			},
+		})
+		if err != nil {
+			log.Printf("Failed to deregister: %v", err)
+		} else {
+			// the master ends the stream once it has processed the
+			// Deregister; cancelling earlier could drop the message
+			for end := time.Now().Add(5 * time.Second); w.connected() && time.Now().Before(end); {
+				time.Sleep(50 * time.Millisecond)
+			}
+		}
+		log.Printf("Worker %s drained (%d tasks unfinished)", w.cfg.WorkerID, len(unfinished))
+		close(w.drained)
+	})
+}
+
+// connected reports whether a session is attached
+func (w *Worker) connected() bool {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+	return w.stream != nil
+}
+
+// unfinished returns the IDs of the tasks whose results were not sent yet
+func (w *Worker) unfinished() []string {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+	ids := make([]string, 0, len(w.tasks))
+	for id := range w.tasks {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// session opens a Connect stream, registers and serves it until it breaks.
+// registered reports whether the master accepted the registration.
+func (w *Worker) session(ctx context.Context, client pb.SchedulerServiceClient) (registered bool, err error) {
+	// 1. [Call Connect] Open a bidirectional stream
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.Connect(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to open stream: %w", err)
+	}
+
+	// 2. [Send registration] then the results held back while disconnected
+	if err := w.attach(stream); err != nil {
+		return false, err
+	}
+	defer w.detach()
+
+	// 3. [Start receiving goroutine]
+	var accepted atomic.Bool
+	recvErr := make(chan error, 1)
+	go func() {
+		recvErr <- w.receiveLoop(stream, &accepted)
+	}()
+
+	// 4. [Heartbeat loop] StatusUpdate as heartbeat/load report, sent on every
+	// tick and additionally whenever a task starts or finishes
+	ticker := time.NewTicker(w.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		periodic := false
+		select {
+		case <-ctx.Done():
+			return accepted.Load(), ctx.Err()
+		case err := <-recvErr:
+			return accepted.Load(), err
+		case <-ticker.C:
+			periodic = true
+		case <-w.statusNow:
+		}
+		if err := w.sendStatus(periodic); err != nil {
+			// if sending fails, it usually means the connection is broken
+			return accepted.Load(), fmt.Errorf("failed to send status update: %w", err)
+		}
+	}
+}
+
+// attach registers on a new stream and makes it the current one. The
+// registration carries the tasks the worker holds, and the outbox is flushed
+// right after it, all under sendMu so no other message gets in between.
+func (w *Worker) attach(stream pb.SchedulerService_ConnectClient) error {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+
+	inFlight := make([]*pb.InFlightTask, 0, len(w.tasks))
+	for _, t := range w.tasks {
+		inFlight = append(inFlight, t)
+	}
+	req := &pb.WorkerMessage{
+		WorkerId: w.cfg.WorkerID,
+		Payload: &pb.WorkerMessage_RegisterRequest{
+			RegisterRequest: &pb.RegisterRequest{
+				Hostname:       w.cfg.WorkerID,
+				MaxConcurrency: w.cfg.MaxConcurrency,
+				Labels:         w.cfg.Labels,
+				Capacity: &pb.Resources{
+					CpuMillis:   w.cfg.CPUMillis,
+					MemoryBytes: w.cfg.MemoryBytes,
+					Named:       w.cfg.Resources,
+				},
+				InFlight: inFlight,
+				Draining: w.draining.Load(),
+			},
+		},
+	}
+	if err := stream.Send(req); err != nil {
+		return fmt.Errorf("failed to send register request: %w", err)
+	}
+	log.Printf("Worker %s successfully sent register request (%d tasks in flight).", w.cfg.WorkerID, len(inFlight))
+
+	for len(w.outbox) > 0 {
+		result := w.outbox[0]
+		if err := stream.Send(resultMessage(w.cfg.WorkerID, result)); err != nil {
+			return fmt.Errorf("failed to resend result for task %s: %w", result.TaskId, err)
+		}
+		w.outbox = w.outbox[1:]
+		w.forget(result.TaskId, result.FencingToken)
+	}
+	w.stream = stream
+	return nil
+}
+
+// detach forgets the current stream; messages sent until the next session
+// are held back or dropped
+func (w *Worker) detach() {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+	w.stream = nil
+}
+
+// receiveLoop handles messages from the master until the stream ends
+func (w *Worker) receiveLoop(stream pb.SchedulerService_ConnectClient, accepted *atomic.Bool) error {
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			// Master closed the connection
+			log.Println("Master closed the connection.")
+			return fmt.Errorf("master closed the connection")
+		}
+		if err != nil {
+			return fmt.Errorf("error receiving message from master: %w", err)
+		}
+
+		switch x := msg.Payload.(type) {
+		case *pb.MasterMessage_RegisterResponse:
+			log.Printf("Successfully registered! Message from master: %s", x.RegisterResponse.Message)
+			accepted.Store(x.RegisterResponse.Success)
+		case *pb.MasterMessage_TaskAssignment:
+			log.Printf("Received new task: %s (%s)", x.TaskAssignment.TaskId, x.TaskAssignment.TaskName)
+			run := w.track(x.TaskAssignment)
+			// ack before queueing; the master revokes unacknowledged assignments
+			if err := w.sendAccepted(x.TaskAssignment); err != nil {
+				return fmt.Errorf("failed to acknowledge task %s: %w", x.TaskAssignment.TaskId, err)
+			}
+			if !run {
+				log.Printf("Task %s is already held; not running it again", x.TaskAssignment.TaskId)
+				continue
+			}
+			w.exec.submit(x.TaskAssignment)
+		case *pb.MasterMessage_CancelTask:
+			w.cancelTask(x.CancelTask)
+		case *pb.MasterMessage_Drain:
+			log.Printf("Master asked to drain: %s", x.Drain.Reason)
+			go w.Drain(x.Drain.Reason, time.Duration(x.Drain.TimeoutMillis)*time.Millisecond)
+		case *pb.MasterMessage_Ping:
+			// answer right away; the master measures the round trip
+			if err := w.sendPong(x.Ping); err != nil {
+				return fmt.Errorf("failed to send pong: %w", err)
+			}
+		default:
+			log.Printf("Received unknown message type from master")
+		}
+	}
+}
+
+// cancelTask stops the attempt of a task the master cancelled; token 0 means
+// the attempt the worker holds. Attempts that already finished are ignored.
+func (w *Worker) cancelTask(c *pb.CancelTask) {
+	token := c.FencingToken
+	if token == 0 {
+		w.sendMu.Lock()
+		if t, ok := w.tasks[c.TaskId]; ok {
+			token = t.Task.FencingToken
+		}
+		w.sendMu.Unlock()
+	}
+	if !w.exec.cancel(c.TaskId, token, c.Reason) {
+		log.Printf("Ignoring cancellation of unknown or finished task %s", c.TaskId)
+		return
+	}
+	log.Printf("Cancelling task %s: %s", c.TaskId, c.Reason)
+}
+
+// track remembers an assignment until its result is sent and reports
+// whether it has to be run. Assignments are deduplicated by task_id: a
+// redelivery of a task the worker still holds takes the held attempt over
+// under its new fencing token instead of running a second copy. Only if the
+// held attempt was cancelled or already finished does the redelivery run.
+func (w *Worker) track(task *pb.TaskAssignment) bool {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+
+	if t, ok := w.tasks[task.TaskId]; ok {
+		held := t.Task.FencingToken
+		if held == task.FencingToken {
+			return false
+		}
+		if w.exec.handover(task.TaskId, held, task.FencingToken) {
+			t.Task = task
+			if t.Started && w.stream != nil {
+				// the master marked the new attempt Assigned; it is running
+				started := &pb.TaskStarted{
+					TaskId:            task.TaskId,
+					StartTimeUnixNano: t.StartTimeUnixNano,
+					FencingToken:      task.FencingToken,
+				}
+				if err := w.stream.Send(&pb.WorkerMessage{
+					WorkerId: w.cfg.WorkerID,
+					Payload:  &pb.WorkerMessage_TaskStarted{TaskStarted: started},
+				}); err != nil {
+					log.Printf("Failed to report start of task %s: %v", task.TaskId, err)
+				}
+			}
+			return false
+		}
+	}
+	if token, ok := w.finished[task.TaskId]; ok && token == task.FencingToken {
+		return false
+	}
+	w.tasks[task.TaskId] = &pb.InFlightTask{Task: task}
+	return true
+}
+
+// forget drops a task once its result was sent, unless the entry already
+// belongs to a newer attempt. Caller must hold sendMu.
+func (w *Worker) forget(taskID string, token uint64) {
+	if t, ok := w.tasks[taskID]; ok && t.Task.FencingToken == token {
+		delete(w.tasks, taskID)
+	}
+
+	if _, ok := w.finished[taskID]; !ok {
+		w.finishedOrder = append(w.finishedOrder, taskID)
+	}
+	w.finished[taskID] = token
+	if len(w.finishedOrder) > finishedHistory {
+		delete(w.finished, w.finishedOrder[0])
+		w.finishedOrder = w.finishedOrder[1:]
+	}
+}
+
+// sendAccepted acknowledges an assignment to the master
+func (w *Worker) sendAccepted(task *pb.TaskAssignment) error {
+	return w.send(&pb.WorkerMessage{
+		WorkerId: w.cfg.WorkerID,
+		Payload: &pb.WorkerMessage_TaskAccepted{
+			TaskAccepted: &pb.TaskAccepted{
+				TaskId:       task.TaskId,
+				FencingToken: task.FencingToken,
+			},
+		},
+	})
+}
+
+// leases lists the attempts the worker holds; every StatusUpdate carries
+// them to renew their leases on the master
+func (w *Worker) leases() []*pb.TaskLease {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+	leases := make([]*pb.TaskLease, 0, len(w.tasks))
+	for id, t := range w.tasks {
+		leases = append(leases, &pb.TaskLease{
+			TaskId:       id,
+			FencingToken: t.Task.FencingToken,
+		})
+	}
+	return leases
+}
+
+// requestStatus schedules an immediate StatusUpdate without blocking; bursts
+// of task starts/finishes collapse into one update
+func (w *Worker) requestStatus() {
+	select {
+	case w.statusNow <- struct{}{}:
+	default:
+	}
+}
+
+// sendStatus sends a StatusUpdate. periodic marks ticker heartbeats, which the
+// master uses to learn the heartbeat interval distribution.
+func (w *Worker) sendStatus(periodic bool) error {
+	active, queued := w.exec.counts()
+
+	// /proc is sampled on the heartbeat period; event-driven updates repeat
+	// the last sample so CPU usage is not computed over tiny intervals
+	metrics := w.metrics.Last()
+	if periodic || metrics == nil {
+		metrics = w.metrics.Sample()
+	}
+	latency, taskLatency := w.exec.latency.stats()
+
+	log.Printf("Sending heartbeat... (Active Tasks: %d, Queued: %d)", active, queued)
+	return w.send(&pb.WorkerMessage{
+		WorkerId: w.cfg.WorkerID,
+		Payload: &pb.WorkerMessage_StatusUpdate{
+			StatusUpdate: &pb.StatusUpdate{
+				ActiveTaskCount: active,
+				QueuedTaskCount: queued,
+				Periodic:        periodic,
+				Metrics:         metrics,
+				Latency:         latency,
+				TaskLatency:     taskLatency,
+				Leases:          w.leases(),
+			},
+		},
+	})
+}
+
+// sendPong answers an RTT probe from the master
+func (w *Worker) sendPong(ping *pb.Ping) error {
+	return w.send(&pb.WorkerMessage{
+		WorkerId: w.cfg.WorkerID,
+		Payload: &pb.WorkerMessage_Pong{
+			Pong: &pb.Pong{
+				Nonce:            ping.Nonce,
+				PingSentUnixNano: ping.SentUnixNano,
+				ReceivedUnixNano: time.Now().UnixNano(),
+			},
+		},
+	})
+}
+
+// reportStarted tells the master a task is now running. While disconnected
+// the start is only recorded; the next registration reports it.
+func (w *Worker) reportStarted(started *pb.TaskStarted) {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+
+	if t, ok := w.tasks[started.TaskId]; ok && t.Task.FencingToken == started.FencingToken {
+		t.Started = true
+		t.StartTimeUnixNano = started.StartTimeUnixNano
+	}
+	if w.stream == nil {
+		return
+	}
+	msg := &pb.WorkerMessage{
+		WorkerId: w.cfg.WorkerID,
+		Payload: &pb.WorkerMessage_TaskStarted{
+			TaskStarted: started,
+		},
+	}
+	if err := w.stream.Send(msg); err != nil {
+		log.Printf("Failed to report start of task %s: %v", started.TaskId, err)
+	}
+}
+
+// reportResult sends a finished task's TaskResult to the master, or keeps it
+// in the outbox until the next session if the connection is down
+func (w *Worker) reportResult(result *pb.TaskResult) {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+
+	if w.stream != nil {
+		err := w.stream.Send(resultMessage(w.cfg.WorkerID, result))
+		if err == nil {
+			w.forget(result.TaskId, result.FencingToken)
+			return
+		}
+		log.Printf("Failed to send result for task %s: %v", result.TaskId, err)
+	}
+	log.Printf("Holding result for task %s until the master is back", result.TaskId)
+	w.outbox = append(w.outbox, result)
+}
+
+// resultMessage wraps a TaskResult
+func resultMessage(workerID string, result *pb.TaskResult) *pb.WorkerMessage {
+	return &pb.WorkerMessage{
+		WorkerId: workerID,
+		Payload: &pb.WorkerMessage_TaskResult{
+			TaskResult: result,
+		},
+	}
+}
+
+// send serializes writes to the stream
+func (w *Worker) send(msg *pb.WorkerMessage) error {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+	if w.stream == nil {
+		return errors.New("not connected to the master")
+	}
+	return w.stream.Send(msg)
+}
+
+// ParseLabels parses a comma-separated list of key=value pairs,
+// e.g. "zone=us-east,gpu=false"
+func ParseLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid label %q, want key=value", pair)
+		}
+		labels[k] = strings.TrimSpace(v)
+	}
+	return labels, nil
+}
+
+// ParseResources parses a comma-separated list of name=amount pairs,
+// e.g. "gpu=2,fpga=1"
+func ParseResources(s string) (map[string]int64, error) {
+	pairs, err := ParseLabels(s)
+	if err != nil {
+		return nil, err
+	}
+	resources := make(map[string]int64, len(pairs))
+	for k, v := range pairs {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid amount %q for resource %s", v, k)
+		}
+		resources[k] = n
+	}
+	return resources, nil
+}
+
+// byteUnits are the suffixes ParseBytes accepts
+var byteUnits = []struct {
+	suffix string
+	factor int64
+}{
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40},
+	{"K", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12},
+}
+
+// ParseBytes parses a memory size such as "512Mi", "4G" or "1048576"
+func ParseBytes(s string) (int64, error) {
+	num, factor := strings.TrimSpace(s), int64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(num, u.suffix) {
+			num, factor = strings.TrimSuffix(num, u.suffix), u.factor
+			break
+		}
+	}
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	return n * factor, nil
+}
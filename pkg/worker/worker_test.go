@@ -0,0 +1,218 @@
+package worker
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/YilinZhang0101/SwiftScheduler/proto" // module path
+)
+
+// fakeStream is a Connect stream that records what the worker sends
+type fakeStream struct {
+	pb.SchedulerService_ConnectClient
+	mu   sync.Mutex
+	sent []*pb.WorkerMessage
+	err  error // returned by Send when set
+}
+
+func (s *fakeStream) Send(msg *pb.WorkerMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+// messages returns what was sent so far
+func (s *fakeStream) messages() []*pb.WorkerMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*pb.WorkerMessage(nil), s.sent...)
+}
+
+func TestTrackDedupe(t *testing.T) {
+	w := New(Config{WorkerID: "w1"})
+	stream := &fakeStream{}
+	w.stream = stream
+	assign := func(token uint64) *pb.TaskAssignment {
+		return &pb.TaskAssignment{TaskId: "t1", TaskName: "noop", FencingToken: token}
+	}
+
+	// the pool is not started, so attempts stay queued
+	if !w.track(assign(1)) {
+		t.Fatal("first delivery not run")
+	}
+	w.exec.submit(assign(1))
+	w.reportStarted(&pb.TaskStarted{TaskId: "t1", StartTimeUnixNano: 42, FencingToken: 1})
+
+	steps := []struct {
+		name  string
+		token uint64
+		run   bool
+		held  uint64 // token of the held attempt afterwards
+	}{
+		{"same attempt redelivered", 1, false, 1},
+		{"redelivered under a new token", 2, false, 2},
+		{"stale token after the handover", 2, false, 2},
+	}
+	for _, s := range steps {
+		if run := w.track(assign(s.token)); run != s.run {
+			t.Errorf("%s: run = %v, want %v", s.name, run, s.run)
+		}
+		if held := w.tasks["t1"].Task.FencingToken; held != s.held {
+			t.Errorf("%s: held token = %d, want %d", s.name, held, s.held)
+		}
+	}
+
+	// the running attempt is reported started again under its new token
+	var started []*pb.TaskStarted
+	for _, msg := range stream.messages() {
+		if s := msg.GetTaskStarted(); s != nil {
+			started = append(started, s)
+		}
+	}
+	if len(started) != 2 || started[1].FencingToken != 2 || started[1].StartTimeUnixNano != 42 {
+		t.Errorf("TaskStarted messages = %v, want a second one for token 2", started)
+	}
+
+	// a cancelled attempt cannot be taken over; the redelivery runs
+	if !w.exec.cancel("t1", 2, "revoked") {
+		t.Fatal("cancel of the handed over attempt failed")
+	}
+	if !w.track(assign(3)) {
+		t.Error("redelivery of a cancelled attempt not run")
+	}
+
+	// once reported, the same attempt is not run again, a newer one is
+	w.reportResult(&pb.TaskResult{TaskId: "t1", Status: pb.TaskStatus_TASK_STATUS_SUCCEEDED, FencingToken: 3})
+	if _, ok := w.tasks["t1"]; ok {
+		t.Error("task still held after its result was sent")
+	}
+	if w.track(assign(3)) {
+		t.Error("finished attempt run again")
+	}
+	if !w.track(assign(4)) {
+		t.Error("new attempt of a finished task not run")
+	}
+}
+
+func TestOutboxReplay(t *testing.T) {
+	w := New(Config{WorkerID: "w1"})
+	for _, id := range []string{"t1", "t2"} {
+		w.track(&pb.TaskAssignment{TaskId: id, FencingToken: 1})
+	}
+
+	// t1 finishes while disconnected
+	w.reportResult(&pb.TaskResult{TaskId: "t1", FencingToken: 1})
+
+	// t2 finishes on a stream that broke
+	broken := &fakeStream{}
+	if err := w.attach(broken); err != nil {
+		t.Fatal(err)
+	}
+	if msgs := broken.messages(); len(msgs) != 2 || msgs[1].GetTaskResult().GetTaskId() != "t1" {
+		t.Errorf("sent %v on the first registration, want it followed by the result of t1", msgs)
+	}
+	broken.err = errors.New("connection reset")
+	w.reportResult(&pb.TaskResult{TaskId: "t2", FencingToken: 1})
+	w.detach()
+	if len(w.outbox) != 1 || w.outbox[0].TaskId != "t2" {
+		t.Fatalf("outbox = %v, want t2", w.outbox)
+	}
+
+	stream := &fakeStream{}
+	if err := w.attach(stream); err != nil {
+		t.Fatal(err)
+	}
+	msgs := stream.messages()
+	if len(msgs) != 2 {
+		t.Fatalf("sent %d messages on reconnect, want registration and one result", len(msgs))
+	}
+	// the registration still lists t2, whose result follows it
+	reg := msgs[0].GetRegisterRequest()
+	if reg == nil || len(reg.InFlight) != 1 || reg.InFlight[0].Task.TaskId != "t2" {
+		t.Errorf("first message = %v, want a registration holding t2", msgs[0])
+	}
+	if r := msgs[1].GetTaskResult(); r == nil || r.TaskId != "t2" {
+		t.Errorf("second message = %v, want the result of t2", msgs[1])
+	}
+	if len(w.outbox) != 0 || len(w.tasks) != 0 {
+		t.Errorf("outbox %v, tasks %v after the replay; want both empty", w.outbox, w.tasks)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	tests := []struct {
+		name       string
+		finish     bool // t1 reports its result before the drain deadline
+		unfinished []string
+	}{
+		{"tasks finish in time", true, nil},
+		{"deadline passes", false, []string{"t1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := New(Config{WorkerID: "w1"})
+			stream := &fakeStream{}
+			w.stream = stream
+			w.track(&pb.TaskAssignment{TaskId: "t1", FencingToken: 1})
+
+			done := make(chan struct{})
+			go func() {
+				w.Drain("shutdown", 300*time.Millisecond)
+				close(done)
+			}()
+			if tt.finish {
+				time.Sleep(50 * time.Millisecond)
+				w.reportResult(&pb.TaskResult{TaskId: "t1", FencingToken: 1})
+			}
+
+			// Drain waits for the master to end the stream after the Deregister
+			var msgs []*pb.WorkerMessage
+			for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+				msgs = stream.messages()
+				if len(msgs) > 0 && msgs[len(msgs)-1].GetDeregister() != nil {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("no Deregister sent: %v", msgs)
+				}
+			}
+			w.detach()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Drain did not return once the stream ended")
+			}
+
+			if msgs[0].GetDrainRequest() == nil {
+				t.Errorf("first message = %v, want a DrainRequest", msgs[0])
+			}
+			if tt.finish && msgs[1].GetTaskResult().GetTaskId() != "t1" {
+				t.Errorf("second message = %v, want the result of t1", msgs[1])
+			}
+			if got := msgs[len(msgs)-1].GetDeregister().UnfinishedTaskIds; !slices.Equal(got, tt.unfinished) {
+				t.Errorf("unfinished tasks = %v, want %v", got, tt.unfinished)
+			}
+			select {
+			case <-w.drained:
+			default:
+				t.Error("worker not marked drained")
+			}
+
+			// a registration after the drain started keeps new tasks away
+			next := &fakeStream{}
+			if err := w.attach(next); err != nil {
+				t.Fatal(err)
+			}
+			if reg := next.messages()[0].GetRegisterRequest(); !reg.GetDraining() {
+				t.Errorf("registration = %v, want it draining", reg)
+			}
+		})
+	}
+}
@@ -21,6 +21,244 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Final outcome of a task
+type TaskStatus int32
+
+const (
+	TaskStatus_TASK_STATUS_UNSPECIFIED TaskStatus = 0
+	TaskStatus_TASK_STATUS_SUCCEEDED   TaskStatus = 1
+	TaskStatus_TASK_STATUS_FAILED      TaskStatus = 2
+	TaskStatus_TASK_STATUS_CANCELLED   TaskStatus = 3 // Stopped after a CancelTask from the master
+	TaskStatus_TASK_STATUS_TIMED_OUT   TaskStatus = 4 // Ran past TaskAssignment.timeout_millis
+)
+
+// Enum value maps for TaskStatus.
+var (
+	TaskStatus_name = map[int32]string{
+		0: "TASK_STATUS_UNSPECIFIED",
+		1: "TASK_STATUS_SUCCEEDED",
+		2: "TASK_STATUS_FAILED",
+		3: "TASK_STATUS_CANCELLED",
+		4: "TASK_STATUS_TIMED_OUT",
+	}
+	TaskStatus_value = map[string]int32{
+		"TASK_STATUS_UNSPECIFIED": 0,
+		"TASK_STATUS_SUCCEEDED":   1,
+		"TASK_STATUS_FAILED":      2,
+		"TASK_STATUS_CANCELLED":   3,
+		"TASK_STATUS_TIMED_OUT":   4,
+	}
+)
+
+func (x TaskStatus) Enum() *TaskStatus {
+	p := new(TaskStatus)
+	*p = x
+	return p
+}
+
+func (x TaskStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TaskStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_scheduler_proto_enumTypes[0].Descriptor()
+}
+
+func (TaskStatus) Type() protoreflect.EnumType {
+	return &file_proto_scheduler_proto_enumTypes[0]
+}
+
+func (x TaskStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TaskStatus.Descriptor instead.
+func (TaskStatus) EnumDescriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{0}
+}
+
+// What kind of error ended an attempt; retry policies select on it
+type ErrorClass int32
+
+const (
+	ErrorClass_ERROR_CLASS_UNSPECIFIED  ErrorClass = 0
+	ErrorClass_ERROR_CLASS_HANDLER      ErrorClass = 1 // The handler returned an error
+	ErrorClass_ERROR_CLASS_PERMANENT    ErrorClass = 2 // The handler marked its error as permanent
+	ErrorClass_ERROR_CLASS_TIMEOUT      ErrorClass = 3 // The task ran past its timeout
+	ErrorClass_ERROR_CLASS_UNKNOWN_TASK ErrorClass = 4 // The worker has no handler for the task_name
+	ErrorClass_ERROR_CLASS_PANIC        ErrorClass = 5 // The handler panicked
+	ErrorClass_ERROR_CLASS_REJECTED     ErrorClass = 6 // The worker's queue was full
+)
+
+// Enum value maps for ErrorClass.
+var (
+	ErrorClass_name = map[int32]string{
+		0: "ERROR_CLASS_UNSPECIFIED",
+		1: "ERROR_CLASS_HANDLER",
+		2: "ERROR_CLASS_PERMANENT",
+		3: "ERROR_CLASS_TIMEOUT",
+		4: "ERROR_CLASS_UNKNOWN_TASK",
+		5: "ERROR_CLASS_PANIC",
+		6: "ERROR_CLASS_REJECTED",
+	}
+	ErrorClass_value = map[string]int32{
+		"ERROR_CLASS_UNSPECIFIED":  0,
+		"ERROR_CLASS_HANDLER":      1,
+		"ERROR_CLASS_PERMANENT":    2,
+		"ERROR_CLASS_TIMEOUT":      3,
+		"ERROR_CLASS_UNKNOWN_TASK": 4,
+		"ERROR_CLASS_PANIC":        5,
+		"ERROR_CLASS_REJECTED":     6,
+	}
+)
+
+func (x ErrorClass) Enum() *ErrorClass {
+	p := new(ErrorClass)
+	*p = x
+	return p
+}
+
+func (x ErrorClass) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorClass) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_scheduler_proto_enumTypes[1].Descriptor()
+}
+
+func (ErrorClass) Type() protoreflect.EnumType {
+	return &file_proto_scheduler_proto_enumTypes[1]
+}
+
+func (x ErrorClass) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorClass.Descriptor instead.
+func (ErrorClass) EnumDescriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{1}
+}
+
+type LabelOperator int32
+
+const (
+	LabelOperator_LABEL_OPERATOR_UNSPECIFIED    LabelOperator = 0
+	LabelOperator_LABEL_OPERATOR_IN             LabelOperator = 1 // label is set to one of values
+	LabelOperator_LABEL_OPERATOR_NOT_IN         LabelOperator = 2 // label is missing or set to none of values
+	LabelOperator_LABEL_OPERATOR_EXISTS         LabelOperator = 3 // label is set
+	LabelOperator_LABEL_OPERATOR_DOES_NOT_EXIST LabelOperator = 4 // label is missing
+)
+
+// Enum value maps for LabelOperator.
+var (
+	LabelOperator_name = map[int32]string{
+		0: "LABEL_OPERATOR_UNSPECIFIED",
+		1: "LABEL_OPERATOR_IN",
+		2: "LABEL_OPERATOR_NOT_IN",
+		3: "LABEL_OPERATOR_EXISTS",
+		4: "LABEL_OPERATOR_DOES_NOT_EXIST",
+	}
+	LabelOperator_value = map[string]int32{
+		"LABEL_OPERATOR_UNSPECIFIED":    0,
+		"LABEL_OPERATOR_IN":             1,
+		"LABEL_OPERATOR_NOT_IN":         2,
+		"LABEL_OPERATOR_EXISTS":         3,
+		"LABEL_OPERATOR_DOES_NOT_EXIST": 4,
+	}
+)
+
+func (x LabelOperator) Enum() *LabelOperator {
+	p := new(LabelOperator)
+	*p = x
+	return p
+}
+
+func (x LabelOperator) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LabelOperator) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_scheduler_proto_enumTypes[2].Descriptor()
+}
+
+func (LabelOperator) Type() protoreflect.EnumType {
+	return &file_proto_scheduler_proto_enumTypes[2]
+}
+
+func (x LabelOperator) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LabelOperator.Descriptor instead.
+func (LabelOperator) EnumDescriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{2}
+}
+
+// Lifecycle state of a task in the master
+type TaskState int32
+
+const (
+	TaskState_TASK_STATE_UNSPECIFIED TaskState = 0
+	TaskState_TASK_STATE_PENDING     TaskState = 1
+	TaskState_TASK_STATE_ASSIGNED    TaskState = 2
+	TaskState_TASK_STATE_RUNNING     TaskState = 3
+	TaskState_TASK_STATE_SUCCEEDED   TaskState = 4
+	TaskState_TASK_STATE_FAILED      TaskState = 5
+	TaskState_TASK_STATE_CANCELLED   TaskState = 6
+	TaskState_TASK_STATE_TIMED_OUT   TaskState = 7
+)
+
+// Enum value maps for TaskState.
+var (
+	TaskState_name = map[int32]string{
+		0: "TASK_STATE_UNSPECIFIED",
+		1: "TASK_STATE_PENDING",
+		2: "TASK_STATE_ASSIGNED",
+		3: "TASK_STATE_RUNNING",
+		4: "TASK_STATE_SUCCEEDED",
+		5: "TASK_STATE_FAILED",
+		6: "TASK_STATE_CANCELLED",
+		7: "TASK_STATE_TIMED_OUT",
+	}
+	TaskState_value = map[string]int32{
+		"TASK_STATE_UNSPECIFIED": 0,
+		"TASK_STATE_PENDING":     1,
+		"TASK_STATE_ASSIGNED":    2,
+		"TASK_STATE_RUNNING":     3,
+		"TASK_STATE_SUCCEEDED":   4,
+		"TASK_STATE_FAILED":      5,
+		"TASK_STATE_CANCELLED":   6,
+		"TASK_STATE_TIMED_OUT":   7,
+	}
+)
+
+func (x TaskState) Enum() *TaskState {
+	p := new(TaskState)
+	*p = x
+	return p
+}
+
+func (x TaskState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TaskState) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_scheduler_proto_enumTypes[3].Descriptor()
+}
+
+func (TaskState) Type() protoreflect.EnumType {
+	return &file_proto_scheduler_proto_enumTypes[3]
+}
+
+func (x TaskState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TaskState.Descriptor instead.
+func (TaskState) EnumDescriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{3}
+}
+
 // --- Worker -> Master ---
 type WorkerMessage struct {
 	state    protoimpl.MessageState `protogen:"open.v1"`
@@ -29,6 +267,12 @@ type WorkerMessage struct {
 	//
 	//	*WorkerMessage_RegisterRequest
 	//	*WorkerMessage_StatusUpdate
+	//	*WorkerMessage_TaskResult
+	//	*WorkerMessage_Pong
+	//	*WorkerMessage_TaskStarted
+	//	*WorkerMessage_DrainRequest
+	//	*WorkerMessage_Deregister
+	//	*WorkerMessage_TaskAccepted
 	Payload       isWorkerMessage_Payload `protobuf_oneof:"payload"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -96,6 +340,60 @@ func (x *WorkerMessage) GetStatusUpdate() *StatusUpdate {
 	return nil
 }
 
+func (x *WorkerMessage) GetTaskResult() *TaskResult {
+	if x != nil {
+		if x, ok := x.Payload.(*WorkerMessage_TaskResult); ok {
+			return x.TaskResult
+		}
+	}
+	return nil
+}
+
+func (x *WorkerMessage) GetPong() *Pong {
+	if x != nil {
+		if x, ok := x.Payload.(*WorkerMessage_Pong); ok {
+			return x.Pong
+		}
+	}
+	return nil
+}
+
+func (x *WorkerMessage) GetTaskStarted() *TaskStarted {
+	if x != nil {
+		if x, ok := x.Payload.(*WorkerMessage_TaskStarted); ok {
+			return x.TaskStarted
+		}
+	}
+	return nil
+}
+
+func (x *WorkerMessage) GetDrainRequest() *DrainRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*WorkerMessage_DrainRequest); ok {
+			return x.DrainRequest
+		}
+	}
+	return nil
+}
+
+func (x *WorkerMessage) GetDeregister() *Deregister {
+	if x != nil {
+		if x, ok := x.Payload.(*WorkerMessage_Deregister); ok {
+			return x.Deregister
+		}
+	}
+	return nil
+}
+
+func (x *WorkerMessage) GetTaskAccepted() *TaskAccepted {
+	if x != nil {
+		if x, ok := x.Payload.(*WorkerMessage_TaskAccepted); ok {
+			return x.TaskAccepted
+		}
+	}
+	return nil
+}
+
 type isWorkerMessage_Payload interface {
 	isWorkerMessage_Payload()
 }
@@ -108,16 +406,59 @@ type WorkerMessage_StatusUpdate struct {
 	StatusUpdate *StatusUpdate `protobuf:"bytes,3,opt,name=status_update,json=statusUpdate,proto3,oneof"` // Sent on task completion or for heartbeats
 }
 
+type WorkerMessage_TaskResult struct {
+	TaskResult *TaskResult `protobuf:"bytes,4,opt,name=task_result,json=taskResult,proto3,oneof"` // Sent when a task finishes
+}
+
+type WorkerMessage_Pong struct {
+	Pong *Pong `protobuf:"bytes,5,opt,name=pong,proto3,oneof"` // Answer to a master Ping
+}
+
+type WorkerMessage_TaskStarted struct {
+	TaskStarted *TaskStarted `protobuf:"bytes,6,opt,name=task_started,json=taskStarted,proto3,oneof"` // Sent when a task leaves the queue and starts running
+}
+
+type WorkerMessage_DrainRequest struct {
+	DrainRequest *DrainRequest `protobuf:"bytes,7,opt,name=drain_request,json=drainRequest,proto3,oneof"` // The worker is shutting down; stop sending it tasks
+}
+
+type WorkerMessage_Deregister struct {
+	Deregister *Deregister `protobuf:"bytes,8,opt,name=deregister,proto3,oneof"` // Last message of a clean shutdown
+}
+
+type WorkerMessage_TaskAccepted struct {
+	TaskAccepted *TaskAccepted `protobuf:"bytes,9,opt,name=task_accepted,json=taskAccepted,proto3,oneof"` // Sent as soon as a TaskAssignment arrives
+}
+
 func (*WorkerMessage_RegisterRequest) isWorkerMessage_Payload() {}
 
 func (*WorkerMessage_StatusUpdate) isWorkerMessage_Payload() {}
 
+func (*WorkerMessage_TaskResult) isWorkerMessage_Payload() {}
+
+func (*WorkerMessage_Pong) isWorkerMessage_Payload() {}
+
+func (*WorkerMessage_TaskStarted) isWorkerMessage_Payload() {}
+
+func (*WorkerMessage_DrainRequest) isWorkerMessage_Payload() {}
+
+func (*WorkerMessage_Deregister) isWorkerMessage_Payload() {}
+
+func (*WorkerMessage_TaskAccepted) isWorkerMessage_Payload() {}
+
 type RegisterRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	Hostname       string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	MaxConcurrency int32                  `protobuf:"varint,2,opt,name=max_concurrency,json=maxConcurrency,proto3" json:"max_concurrency,omitempty"` // The max number of tasks this worker can run
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	MaxConcurrency int32                  `protobuf:"varint,2,opt,name=max_concurrency,json=maxConcurrency,proto3" json:"max_concurrency,omitempty"`                                    // The max number of tasks this worker can run
+	Labels         map[string]string      `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // e.g. zone=us-east, gpu=false, pool=batch; matched by task placement
+	Capacity       *Resources             `protobuf:"bytes,4,opt,name=capacity,proto3" json:"capacity,omitempty"`                                                                       // What the worker offers; the master tracks how much of it is allocated
+	// Tasks the worker still holds when it re-registers after a lost
+	// connection: queued, running, or finished with the result not sent yet.
+	// The master adopts them instead of running them again.
+	InFlight      []*InFlightTask `protobuf:"bytes,5,rep,name=in_flight,json=inFlight,proto3" json:"in_flight,omitempty"`
+	Draining      bool            `protobuf:"varint,6,opt,name=draining,proto3" json:"draining,omitempty"` // Re-registering in the middle of a drain
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RegisterRequest) Reset() {
@@ -164,27 +505,57 @@ func (x *RegisterRequest) GetMaxConcurrency() int32 {
 	return 0
 }
 
-type StatusUpdate struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	ActiveTaskCount int32                  `protobuf:"varint,1,opt,name=active_task_count,json=activeTaskCount,proto3" json:"active_task_count,omitempty"` // Current number of tasks being processed
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+func (x *RegisterRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
 }
 
-func (x *StatusUpdate) Reset() {
-	*x = StatusUpdate{}
+func (x *RegisterRequest) GetCapacity() *Resources {
+	if x != nil {
+		return x.Capacity
+	}
+	return nil
+}
+
+func (x *RegisterRequest) GetInFlight() []*InFlightTask {
+	if x != nil {
+		return x.InFlight
+	}
+	return nil
+}
+
+func (x *RegisterRequest) GetDraining() bool {
+	if x != nil {
+		return x.Draining
+	}
+	return false
+}
+
+type InFlightTask struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Task              *TaskAssignment        `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	Started           bool                   `protobuf:"varint,2,opt,name=started,proto3" json:"started,omitempty"`
+	StartTimeUnixNano int64                  `protobuf:"varint,3,opt,name=start_time_unix_nano,json=startTimeUnixNano,proto3" json:"start_time_unix_nano,omitempty"` // Set when started
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *InFlightTask) Reset() {
+	*x = InFlightTask{}
 	mi := &file_proto_scheduler_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StatusUpdate) String() string {
+func (x *InFlightTask) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StatusUpdate) ProtoMessage() {}
+func (*InFlightTask) ProtoMessage() {}
 
-func (x *StatusUpdate) ProtoReflect() protoreflect.Message {
+func (x *InFlightTask) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_scheduler_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -196,44 +567,58 @@ func (x *StatusUpdate) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StatusUpdate.ProtoReflect.Descriptor instead.
-func (*StatusUpdate) Descriptor() ([]byte, []int) {
+// Deprecated: Use InFlightTask.ProtoReflect.Descriptor instead.
+func (*InFlightTask) Descriptor() ([]byte, []int) {
 	return file_proto_scheduler_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *StatusUpdate) GetActiveTaskCount() int32 {
+func (x *InFlightTask) GetTask() *TaskAssignment {
 	if x != nil {
-		return x.ActiveTaskCount
+		return x.Task
+	}
+	return nil
+}
+
+func (x *InFlightTask) GetStarted() bool {
+	if x != nil {
+		return x.Started
+	}
+	return false
+}
+
+func (x *InFlightTask) GetStartTimeUnixNano() int64 {
+	if x != nil {
+		return x.StartTimeUnixNano
 	}
 	return 0
 }
 
-// --- Master -> Worker ---
-type MasterMessage struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Types that are valid to be assigned to Payload:
-	//
-	//	*MasterMessage_RegisterResponse
-	//	*MasterMessage_TaskAssignment
-	Payload       isMasterMessage_Payload `protobuf_oneof:"payload"`
+// Resources is an amount of CPU, memory and named resources (e.g. gpu=2).
+// As a worker capacity, zero cpu_millis or memory_bytes means "not advertised"
+// and is not enforced; a named resource a worker does not list is not available.
+type Resources struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CpuMillis     int64                  `protobuf:"varint,1,opt,name=cpu_millis,json=cpuMillis,proto3" json:"cpu_millis,omitempty"` // 1000 = one core
+	MemoryBytes   int64                  `protobuf:"varint,2,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
+	Named         map[string]int64       `protobuf:"bytes,3,rep,name=named,proto3" json:"named,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MasterMessage) Reset() {
-	*x = MasterMessage{}
+func (x *Resources) Reset() {
+	*x = Resources{}
 	mi := &file_proto_scheduler_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MasterMessage) String() string {
+func (x *Resources) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MasterMessage) ProtoMessage() {}
+func (*Resources) ProtoMessage() {}
 
-func (x *MasterMessage) ProtoReflect() protoreflect.Message {
+func (x *Resources) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_scheduler_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -245,74 +630,60 @@ func (x *MasterMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MasterMessage.ProtoReflect.Descriptor instead.
-func (*MasterMessage) Descriptor() ([]byte, []int) {
+// Deprecated: Use Resources.ProtoReflect.Descriptor instead.
+func (*Resources) Descriptor() ([]byte, []int) {
 	return file_proto_scheduler_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *MasterMessage) GetPayload() isMasterMessage_Payload {
+func (x *Resources) GetCpuMillis() int64 {
 	if x != nil {
-		return x.Payload
+		return x.CpuMillis
 	}
-	return nil
+	return 0
 }
 
-func (x *MasterMessage) GetRegisterResponse() *RegisterResponse {
+func (x *Resources) GetMemoryBytes() int64 {
 	if x != nil {
-		if x, ok := x.Payload.(*MasterMessage_RegisterResponse); ok {
-			return x.RegisterResponse
-		}
+		return x.MemoryBytes
 	}
-	return nil
+	return 0
 }
 
-func (x *MasterMessage) GetTaskAssignment() *TaskAssignment {
+func (x *Resources) GetNamed() map[string]int64 {
 	if x != nil {
-		if x, ok := x.Payload.(*MasterMessage_TaskAssignment); ok {
-			return x.TaskAssignment
-		}
+		return x.Named
 	}
 	return nil
 }
 
-type isMasterMessage_Payload interface {
-	isMasterMessage_Payload()
-}
-
-type MasterMessage_RegisterResponse struct {
-	RegisterResponse *RegisterResponse `protobuf:"bytes,1,opt,name=register_response,json=registerResponse,proto3,oneof"` // Confirms registration
-}
-
-type MasterMessage_TaskAssignment struct {
-	TaskAssignment *TaskAssignment `protobuf:"bytes,2,opt,name=task_assignment,json=taskAssignment,proto3,oneof"` // Pushes a new task to the worker
-}
-
-func (*MasterMessage_RegisterResponse) isMasterMessage_Payload() {}
-
-func (*MasterMessage_TaskAssignment) isMasterMessage_Payload() {}
-
-type RegisterResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+type StatusUpdate struct {
+	state           protoimpl.MessageState   `protogen:"open.v1"`
+	ActiveTaskCount int32                    `protobuf:"varint,1,opt,name=active_task_count,json=activeTaskCount,proto3" json:"active_task_count,omitempty"`                                                            // Current number of tasks being processed
+	QueuedTaskCount int32                    `protobuf:"varint,2,opt,name=queued_task_count,json=queuedTaskCount,proto3" json:"queued_task_count,omitempty"`                                                            // Tasks received but waiting for a free slot
+	Periodic        bool                     `protobuf:"varint,3,opt,name=periodic,proto3" json:"periodic,omitempty"`                                                                                                   // True for ticker heartbeats, false for event-driven updates
+	Metrics         *NodeMetrics             `protobuf:"bytes,4,opt,name=metrics,proto3" json:"metrics,omitempty"`                                                                                                      // Host and cgroup metrics, refreshed on periodic heartbeats; unset if unavailable
+	Latency         *LatencyStats            `protobuf:"bytes,5,opt,name=latency,proto3" json:"latency,omitempty"`                                                                                                      // Execution latency of recent tasks, all names together
+	TaskLatency     map[string]*LatencyStats `protobuf:"bytes,6,rep,name=task_latency,json=taskLatency,proto3" json:"task_latency,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Same, per task_name
+	// Every attempt the worker holds; renews the master's lease on each of them
+	Leases        []*TaskLease `protobuf:"bytes,7,rep,name=leases,proto3" json:"leases,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RegisterResponse) Reset() {
-	*x = RegisterResponse{}
+func (x *StatusUpdate) Reset() {
+	*x = StatusUpdate{}
 	mi := &file_proto_scheduler_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RegisterResponse) String() string {
+func (x *StatusUpdate) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RegisterResponse) ProtoMessage() {}
+func (*StatusUpdate) ProtoMessage() {}
 
-func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+func (x *StatusUpdate) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_scheduler_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -324,49 +695,2329 @@ func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
-func (*RegisterResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use StatusUpdate.ProtoReflect.Descriptor instead.
+func (*StatusUpdate) Descriptor() ([]byte, []int) {
 	return file_proto_scheduler_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *RegisterResponse) GetSuccess() bool {
+func (x *StatusUpdate) GetActiveTaskCount() int32 {
 	if x != nil {
-		return x.Success
+		return x.ActiveTaskCount
 	}
-	return false
+	return 0
+}
+
+func (x *StatusUpdate) GetQueuedTaskCount() int32 {
+	if x != nil {
+		return x.QueuedTaskCount
+	}
+	return 0
+}
+
+func (x *StatusUpdate) GetPeriodic() bool {
+	if x != nil {
+		return x.Periodic
+	}
+	return false
+}
+
+func (x *StatusUpdate) GetMetrics() *NodeMetrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *StatusUpdate) GetLatency() *LatencyStats {
+	if x != nil {
+		return x.Latency
+	}
+	return nil
+}
+
+func (x *StatusUpdate) GetTaskLatency() map[string]*LatencyStats {
+	if x != nil {
+		return x.TaskLatency
+	}
+	return nil
+}
+
+func (x *StatusUpdate) GetLeases() []*TaskLease {
+	if x != nil {
+		return x.Leases
+	}
+	return nil
+}
+
+// NodeMetrics is what the worker reads from /proc and its cgroup (Linux only)
+type NodeMetrics struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	CpuUsage               float64                `protobuf:"fixed64,1,opt,name=cpu_usage,json=cpuUsage,proto3" json:"cpu_usage,omitempty"` // Busy share of all host CPUs since the previous sample, 0-1
+	NumCpus                int32                  `protobuf:"varint,2,opt,name=num_cpus,json=numCpus,proto3" json:"num_cpus,omitempty"`
+	MemTotalBytes          int64                  `protobuf:"varint,3,opt,name=mem_total_bytes,json=memTotalBytes,proto3" json:"mem_total_bytes,omitempty"`
+	MemAvailableBytes      int64                  `protobuf:"varint,4,opt,name=mem_available_bytes,json=memAvailableBytes,proto3" json:"mem_available_bytes,omitempty"`
+	Load1                  float64                `protobuf:"fixed64,5,opt,name=load1,proto3" json:"load1,omitempty"` // /proc/loadavg
+	Load5                  float64                `protobuf:"fixed64,6,opt,name=load5,proto3" json:"load5,omitempty"`
+	Load15                 float64                `protobuf:"fixed64,7,opt,name=load15,proto3" json:"load15,omitempty"`
+	CgroupCpuLimitMillis   int64                  `protobuf:"varint,8,opt,name=cgroup_cpu_limit_millis,json=cgroupCpuLimitMillis,proto3" json:"cgroup_cpu_limit_millis,omitempty"`       // CPU quota of the worker's cgroup, 0 = unlimited
+	CgroupMemoryLimitBytes int64                  `protobuf:"varint,9,opt,name=cgroup_memory_limit_bytes,json=cgroupMemoryLimitBytes,proto3" json:"cgroup_memory_limit_bytes,omitempty"` // 0 = unlimited
+	CgroupMemoryUsageBytes int64                  `protobuf:"varint,10,opt,name=cgroup_memory_usage_bytes,json=cgroupMemoryUsageBytes,proto3" json:"cgroup_memory_usage_bytes,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *NodeMetrics) Reset() {
+	*x = NodeMetrics{}
+	mi := &file_proto_scheduler_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeMetrics) ProtoMessage() {}
+
+func (x *NodeMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeMetrics.ProtoReflect.Descriptor instead.
+func (*NodeMetrics) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *NodeMetrics) GetCpuUsage() float64 {
+	if x != nil {
+		return x.CpuUsage
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetNumCpus() int32 {
+	if x != nil {
+		return x.NumCpus
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetMemTotalBytes() int64 {
+	if x != nil {
+		return x.MemTotalBytes
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetMemAvailableBytes() int64 {
+	if x != nil {
+		return x.MemAvailableBytes
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetLoad1() float64 {
+	if x != nil {
+		return x.Load1
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetLoad5() float64 {
+	if x != nil {
+		return x.Load5
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetLoad15() float64 {
+	if x != nil {
+		return x.Load15
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetCgroupCpuLimitMillis() int64 {
+	if x != nil {
+		return x.CgroupCpuLimitMillis
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetCgroupMemoryLimitBytes() int64 {
+	if x != nil {
+		return x.CgroupMemoryLimitBytes
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetCgroupMemoryUsageBytes() int64 {
+	if x != nil {
+		return x.CgroupMemoryUsageBytes
+	}
+	return 0
+}
+
+// LatencyStats summarizes the execution time of a window of recent tasks
+type LatencyStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	P50Nanos      int64                  `protobuf:"varint,1,opt,name=p50_nanos,json=p50Nanos,proto3" json:"p50_nanos,omitempty"`
+	P99Nanos      int64                  `protobuf:"varint,2,opt,name=p99_nanos,json=p99Nanos,proto3" json:"p99_nanos,omitempty"`
+	Samples       int32                  `protobuf:"varint,3,opt,name=samples,proto3" json:"samples,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LatencyStats) Reset() {
+	*x = LatencyStats{}
+	mi := &file_proto_scheduler_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LatencyStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LatencyStats) ProtoMessage() {}
+
+func (x *LatencyStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LatencyStats.ProtoReflect.Descriptor instead.
+func (*LatencyStats) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *LatencyStats) GetP50Nanos() int64 {
+	if x != nil {
+		return x.P50Nanos
+	}
+	return 0
+}
+
+func (x *LatencyStats) GetP99Nanos() int64 {
+	if x != nil {
+		return x.P99Nanos
+	}
+	return 0
+}
+
+func (x *LatencyStats) GetSamples() int32 {
+	if x != nil {
+		return x.Samples
+	}
+	return 0
+}
+
+type TaskStarted struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	TaskId            string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	StartTimeUnixNano int64                  `protobuf:"varint,2,opt,name=start_time_unix_nano,json=startTimeUnixNano,proto3" json:"start_time_unix_nano,omitempty"`
+	FencingToken      uint64                 `protobuf:"varint,3,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"` // Echo of TaskAssignment.fencing_token
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *TaskStarted) Reset() {
+	*x = TaskStarted{}
+	mi := &file_proto_scheduler_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskStarted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskStarted) ProtoMessage() {}
+
+func (x *TaskStarted) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskStarted.ProtoReflect.Descriptor instead.
+func (*TaskStarted) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TaskStarted) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskStarted) GetStartTimeUnixNano() int64 {
+	if x != nil {
+		return x.StartTimeUnixNano
+	}
+	return 0
+}
+
+func (x *TaskStarted) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
+// Acknowledges the receipt of a TaskAssignment and starts its lease
+type TaskAccepted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	FencingToken  uint64                 `protobuf:"varint,2,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskAccepted) Reset() {
+	*x = TaskAccepted{}
+	mi := &file_proto_scheduler_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskAccepted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskAccepted) ProtoMessage() {}
+
+func (x *TaskAccepted) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskAccepted.ProtoReflect.Descriptor instead.
+func (*TaskAccepted) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TaskAccepted) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskAccepted) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
+type TaskLease struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	FencingToken  uint64                 `protobuf:"varint,2,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskLease) Reset() {
+	*x = TaskLease{}
+	mi := &file_proto_scheduler_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskLease) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskLease) ProtoMessage() {}
+
+func (x *TaskLease) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskLease.ProtoReflect.Descriptor instead.
+func (*TaskLease) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *TaskLease) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskLease) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
+type TaskResult struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	TaskId            string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Status            TaskStatus             `protobuf:"varint,2,opt,name=status,proto3,enum=scheduler.TaskStatus" json:"status,omitempty"`
+	Output            []byte                 `protobuf:"bytes,3,opt,name=output,proto3" json:"output,omitempty"`                                                      // The serialized task return value
+	Error             string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`                                                        // Set when status is not SUCCEEDED
+	StartTimeUnixNano int64                  `protobuf:"varint,5,opt,name=start_time_unix_nano,json=startTimeUnixNano,proto3" json:"start_time_unix_nano,omitempty"`  // When the worker started executing
+	EndTimeUnixNano   int64                  `protobuf:"varint,6,opt,name=end_time_unix_nano,json=endTimeUnixNano,proto3" json:"end_time_unix_nano,omitempty"`        // When the worker finished executing
+	ErrorClass        ErrorClass             `protobuf:"varint,7,opt,name=error_class,json=errorClass,proto3,enum=scheduler.ErrorClass" json:"error_class,omitempty"` // Set when status is FAILED or TIMED_OUT
+	FencingToken      uint64                 `protobuf:"varint,8,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`                     // Echo of TaskAssignment.fencing_token
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *TaskResult) Reset() {
+	*x = TaskResult{}
+	mi := &file_proto_scheduler_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskResult) ProtoMessage() {}
+
+func (x *TaskResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskResult.ProtoReflect.Descriptor instead.
+func (*TaskResult) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TaskResult) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskResult) GetStatus() TaskStatus {
+	if x != nil {
+		return x.Status
+	}
+	return TaskStatus_TASK_STATUS_UNSPECIFIED
+}
+
+func (x *TaskResult) GetOutput() []byte {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *TaskResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *TaskResult) GetStartTimeUnixNano() int64 {
+	if x != nil {
+		return x.StartTimeUnixNano
+	}
+	return 0
+}
+
+func (x *TaskResult) GetEndTimeUnixNano() int64 {
+	if x != nil {
+		return x.EndTimeUnixNano
+	}
+	return 0
+}
+
+func (x *TaskResult) GetErrorClass() ErrorClass {
+	if x != nil {
+		return x.ErrorClass
+	}
+	return ErrorClass_ERROR_CLASS_UNSPECIFIED
+}
+
+func (x *TaskResult) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
+type DrainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DrainRequest) Reset() {
+	*x = DrainRequest{}
+	mi := &file_proto_scheduler_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainRequest) ProtoMessage() {}
+
+func (x *DrainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainRequest.ProtoReflect.Descriptor instead.
+func (*DrainRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DrainRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type Deregister struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Reason string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Tasks still running when the drain deadline passed; the master requeues them
+	UnfinishedTaskIds []string `protobuf:"bytes,2,rep,name=unfinished_task_ids,json=unfinishedTaskIds,proto3" json:"unfinished_task_ids,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Deregister) Reset() {
+	*x = Deregister{}
+	mi := &file_proto_scheduler_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Deregister) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Deregister) ProtoMessage() {}
+
+func (x *Deregister) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Deregister.ProtoReflect.Descriptor instead.
+func (*Deregister) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Deregister) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Deregister) GetUnfinishedTaskIds() []string {
+	if x != nil {
+		return x.UnfinishedTaskIds
+	}
+	return nil
+}
+
+type Pong struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Nonce            uint64                 `protobuf:"varint,1,opt,name=nonce,proto3" json:"nonce,omitempty"`                                                   // Echo of Ping.nonce
+	PingSentUnixNano int64                  `protobuf:"varint,2,opt,name=ping_sent_unix_nano,json=pingSentUnixNano,proto3" json:"ping_sent_unix_nano,omitempty"` // Echo of Ping.sent_unix_nano
+	ReceivedUnixNano int64                  `protobuf:"varint,3,opt,name=received_unix_nano,json=receivedUnixNano,proto3" json:"received_unix_nano,omitempty"`   // Worker wall clock when the Ping arrived
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Pong) Reset() {
+	*x = Pong{}
+	mi := &file_proto_scheduler_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pong) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pong) ProtoMessage() {}
+
+func (x *Pong) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pong.ProtoReflect.Descriptor instead.
+func (*Pong) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Pong) GetNonce() uint64 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+func (x *Pong) GetPingSentUnixNano() int64 {
+	if x != nil {
+		return x.PingSentUnixNano
+	}
+	return 0
+}
+
+func (x *Pong) GetReceivedUnixNano() int64 {
+	if x != nil {
+		return x.ReceivedUnixNano
+	}
+	return 0
+}
+
+// --- Master -> Worker ---
+type MasterMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*MasterMessage_RegisterResponse
+	//	*MasterMessage_TaskAssignment
+	//	*MasterMessage_Ping
+	//	*MasterMessage_Drain
+	//	*MasterMessage_CancelTask
+	Payload       isMasterMessage_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MasterMessage) Reset() {
+	*x = MasterMessage{}
+	mi := &file_proto_scheduler_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MasterMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MasterMessage) ProtoMessage() {}
+
+func (x *MasterMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MasterMessage.ProtoReflect.Descriptor instead.
+func (*MasterMessage) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *MasterMessage) GetPayload() isMasterMessage_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *MasterMessage) GetRegisterResponse() *RegisterResponse {
+	if x != nil {
+		if x, ok := x.Payload.(*MasterMessage_RegisterResponse); ok {
+			return x.RegisterResponse
+		}
+	}
+	return nil
+}
+
+func (x *MasterMessage) GetTaskAssignment() *TaskAssignment {
+	if x != nil {
+		if x, ok := x.Payload.(*MasterMessage_TaskAssignment); ok {
+			return x.TaskAssignment
+		}
+	}
+	return nil
+}
+
+func (x *MasterMessage) GetPing() *Ping {
+	if x != nil {
+		if x, ok := x.Payload.(*MasterMessage_Ping); ok {
+			return x.Ping
+		}
+	}
+	return nil
+}
+
+func (x *MasterMessage) GetDrain() *Drain {
+	if x != nil {
+		if x, ok := x.Payload.(*MasterMessage_Drain); ok {
+			return x.Drain
+		}
+	}
+	return nil
+}
+
+func (x *MasterMessage) GetCancelTask() *CancelTask {
+	if x != nil {
+		if x, ok := x.Payload.(*MasterMessage_CancelTask); ok {
+			return x.CancelTask
+		}
+	}
+	return nil
+}
+
+type isMasterMessage_Payload interface {
+	isMasterMessage_Payload()
+}
+
+type MasterMessage_RegisterResponse struct {
+	RegisterResponse *RegisterResponse `protobuf:"bytes,1,opt,name=register_response,json=registerResponse,proto3,oneof"` // Confirms registration
+}
+
+type MasterMessage_TaskAssignment struct {
+	TaskAssignment *TaskAssignment `protobuf:"bytes,2,opt,name=task_assignment,json=taskAssignment,proto3,oneof"` // Pushes a new task to the worker
+}
+
+type MasterMessage_Ping struct {
+	Ping *Ping `protobuf:"bytes,3,opt,name=ping,proto3,oneof"` // RTT probe; the worker answers with a Pong
+}
+
+type MasterMessage_Drain struct {
+	Drain *Drain `protobuf:"bytes,4,opt,name=drain,proto3,oneof"` // Finish in-flight tasks, deregister and exit
+}
+
+type MasterMessage_CancelTask struct {
+	CancelTask *CancelTask `protobuf:"bytes,5,opt,name=cancel_task,json=cancelTask,proto3,oneof"` // Stop a queued or running task
+}
+
+func (*MasterMessage_RegisterResponse) isMasterMessage_Payload() {}
+
+func (*MasterMessage_TaskAssignment) isMasterMessage_Payload() {}
+
+func (*MasterMessage_Ping) isMasterMessage_Payload() {}
+
+func (*MasterMessage_Drain) isMasterMessage_Payload() {}
+
+func (*MasterMessage_CancelTask) isMasterMessage_Payload() {}
+
+type CancelTask struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	FencingToken  uint64                 `protobuf:"varint,3,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"` // The attempt to stop; 0 = whichever the worker holds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTask) Reset() {
+	*x = CancelTask{}
+	mi := &file_proto_scheduler_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTask) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTask) ProtoMessage() {}
+
+func (x *CancelTask) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTask.ProtoReflect.Descriptor instead.
+func (*CancelTask) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CancelTask) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *CancelTask) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *CancelTask) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
+type Drain struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	TimeoutMillis int64                  `protobuf:"varint,2,opt,name=timeout_millis,json=timeoutMillis,proto3" json:"timeout_millis,omitempty"` // How long to wait for in-flight tasks; 0 = worker default
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Drain) Reset() {
+	*x = Drain{}
+	mi := &file_proto_scheduler_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Drain) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Drain) ProtoMessage() {}
+
+func (x *Drain) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Drain.ProtoReflect.Descriptor instead.
+func (*Drain) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Drain) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Drain) GetTimeoutMillis() int64 {
+	if x != nil {
+		return x.TimeoutMillis
+	}
+	return 0
+}
+
+type RegisterResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterResponse) Reset() {
+	*x = RegisterResponse{}
+	mi := &file_proto_scheduler_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterResponse) ProtoMessage() {}
+
+func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
+func (*RegisterResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RegisterResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RegisterResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type TaskAssignment struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	TaskId      string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	TaskName    string                 `protobuf:"bytes,2,opt,name=task_name,json=taskName,proto3" json:"task_name,omitempty"`
+	TaskPayload []byte                 `protobuf:"bytes,3,opt,name=task_payload,json=taskPayload,proto3" json:"task_payload,omitempty"`                                              // The serialized task arguments
+	Attempt     int32                  `protobuf:"varint,4,opt,name=attempt,proto3" json:"attempt,omitempty"`                                                                        // 1 on first dispatch, incremented every time the task is requeued
+	Placement   *Placement             `protobuf:"bytes,5,opt,name=placement,proto3" json:"placement,omitempty"`                                                                     // Used by the master to pick a worker; workers ignore it
+	Requests    *Resources             `protobuf:"bytes,6,opt,name=requests,proto3" json:"requests,omitempty"`                                                                       // Reserved on the worker while the task is in flight
+	Labels      map[string]string      `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Task labels, e.g. job=nightly; used to select tasks, not workers
+	// Execution time limit counted from when the task starts on the worker;
+	// 0 = none. The worker cancels the handler's context when it passes.
+	TimeoutMillis int64        `protobuf:"varint,8,opt,name=timeout_millis,json=timeoutMillis,proto3" json:"timeout_millis,omitempty"`
+	RetryPolicy   *RetryPolicy `protobuf:"bytes,9,opt,name=retry_policy,json=retryPolicy,proto3" json:"retry_policy,omitempty"` // Used by the master; workers ignore it
+	// Identifies this attempt; it increases with every dispatch. The worker
+	// echoes it in TaskAccepted, TaskStarted, TaskResult and its lease
+	// renewals, and the master drops messages of attempts it has superseded.
+	FencingToken   uint64 `protobuf:"varint,10,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
+	IdempotencyKey string `protobuf:"bytes,11,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // Used by the master; workers ignore it
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TaskAssignment) Reset() {
+	*x = TaskAssignment{}
+	mi := &file_proto_scheduler_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskAssignment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskAssignment) ProtoMessage() {}
+
+func (x *TaskAssignment) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskAssignment.ProtoReflect.Descriptor instead.
+func (*TaskAssignment) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *TaskAssignment) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskAssignment) GetTaskName() string {
+	if x != nil {
+		return x.TaskName
+	}
+	return ""
+}
+
+func (x *TaskAssignment) GetTaskPayload() []byte {
+	if x != nil {
+		return x.TaskPayload
+	}
+	return nil
+}
+
+func (x *TaskAssignment) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *TaskAssignment) GetPlacement() *Placement {
+	if x != nil {
+		return x.Placement
+	}
+	return nil
+}
+
+func (x *TaskAssignment) GetRequests() *Resources {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+func (x *TaskAssignment) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *TaskAssignment) GetTimeoutMillis() int64 {
+	if x != nil {
+		return x.TimeoutMillis
+	}
+	return 0
+}
+
+func (x *TaskAssignment) GetRetryPolicy() *RetryPolicy {
+	if x != nil {
+		return x.RetryPolicy
+	}
+	return nil
+}
+
+func (x *TaskAssignment) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
+func (x *TaskAssignment) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+// RetryPolicy decides whether a failed attempt is dispatched again and when.
+// The n-th retry waits backoff_base * 2^(n-1), capped at backoff_max.
+type RetryPolicy struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	MaxAttempts int32                  `protobuf:"varint,1,opt,name=max_attempts,json=maxAttempts,proto3" json:"max_attempts,omitempty"` // Including the first one; 0 and 1 mean no retries.
+	// Attempts lost with their worker do not count.
+	BackoffBaseMillis int64   `protobuf:"varint,2,opt,name=backoff_base_millis,json=backoffBaseMillis,proto3" json:"backoff_base_millis,omitempty"` // Delay before the first retry; 0 = retry right away
+	BackoffMaxMillis  int64   `protobuf:"varint,3,opt,name=backoff_max_millis,json=backoffMaxMillis,proto3" json:"backoff_max_millis,omitempty"`    // 0 = no cap
+	Jitter            float64 `protobuf:"fixed64,4,opt,name=jitter,proto3" json:"jitter,omitempty"`                                                 // 0..1; each delay is shortened by up to this fraction at random
+	// Error classes worth retrying; empty = every class except PERMANENT
+	RetryOn       []ErrorClass `protobuf:"varint,5,rep,packed,name=retry_on,json=retryOn,proto3,enum=scheduler.ErrorClass" json:"retry_on,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetryPolicy) Reset() {
+	*x = RetryPolicy{}
+	mi := &file_proto_scheduler_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryPolicy) ProtoMessage() {}
+
+func (x *RetryPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryPolicy.ProtoReflect.Descriptor instead.
+func (*RetryPolicy) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RetryPolicy) GetMaxAttempts() int32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetBackoffBaseMillis() int64 {
+	if x != nil {
+		return x.BackoffBaseMillis
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetBackoffMaxMillis() int64 {
+	if x != nil {
+		return x.BackoffMaxMillis
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetJitter() float64 {
+	if x != nil {
+		return x.Jitter
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetRetryOn() []ErrorClass {
+	if x != nil {
+		return x.RetryOn
+	}
+	return nil
+}
+
+// Placement restricts which workers may run a task, based on the labels they
+// registered with. All three parts must be satisfied.
+type Placement struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeSelector  map[string]string      `protobuf:"bytes,1,rep,name=node_selector,json=nodeSelector,proto3" json:"node_selector,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // every key must have exactly this value
+	Affinity      []*LabelExpression     `protobuf:"bytes,2,rep,name=affinity,proto3" json:"affinity,omitempty"`                                                                                                       // every expression must match
+	AntiAffinity  []*LabelExpression     `protobuf:"bytes,3,rep,name=anti_affinity,json=antiAffinity,proto3" json:"anti_affinity,omitempty"`                                                                           // no expression may match
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Placement) Reset() {
+	*x = Placement{}
+	mi := &file_proto_scheduler_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Placement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Placement) ProtoMessage() {}
+
+func (x *Placement) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Placement.ProtoReflect.Descriptor instead.
+func (*Placement) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Placement) GetNodeSelector() map[string]string {
+	if x != nil {
+		return x.NodeSelector
+	}
+	return nil
+}
+
+func (x *Placement) GetAffinity() []*LabelExpression {
+	if x != nil {
+		return x.Affinity
+	}
+	return nil
+}
+
+func (x *Placement) GetAntiAffinity() []*LabelExpression {
+	if x != nil {
+		return x.AntiAffinity
+	}
+	return nil
+}
+
+type LabelExpression struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Operator      LabelOperator          `protobuf:"varint,2,opt,name=operator,proto3,enum=scheduler.LabelOperator" json:"operator,omitempty"`
+	Values        []string               `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty"` // Required for IN and NOT_IN, must be empty otherwise
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LabelExpression) Reset() {
+	*x = LabelExpression{}
+	mi := &file_proto_scheduler_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LabelExpression) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LabelExpression) ProtoMessage() {}
+
+func (x *LabelExpression) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LabelExpression.ProtoReflect.Descriptor instead.
+func (*LabelExpression) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *LabelExpression) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *LabelExpression) GetOperator() LabelOperator {
+	if x != nil {
+		return x.Operator
+	}
+	return LabelOperator_LABEL_OPERATOR_UNSPECIFIED
+}
+
+func (x *LabelExpression) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// Application-level RTT probe. gRPC does not expose HTTP/2 PING ACK timing,
+// so the master measures round trips over the Connect stream itself.
+type Ping struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nonce         uint64                 `protobuf:"varint,1,opt,name=nonce,proto3" json:"nonce,omitempty"`                                     // Monotonically increasing per connection
+	SentUnixNano  int64                  `protobuf:"varint,2,opt,name=sent_unix_nano,json=sentUnixNano,proto3" json:"sent_unix_nano,omitempty"` // Master wall clock when sent (informational)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ping) Reset() {
+	*x = Ping{}
+	mi := &file_proto_scheduler_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ping) ProtoMessage() {}
+
+func (x *Ping) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ping.ProtoReflect.Descriptor instead.
+func (*Ping) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Ping) GetNonce() uint64 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+func (x *Ping) GetSentUnixNano() int64 {
+	if x != nil {
+		return x.SentUnixNano
+	}
+	return 0
+}
+
+// --- Client <-> Master (TaskService) ---
+type SubmitTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"` // Optional; the master generates one when empty
+	TaskName      string                 `protobuf:"bytes,2,opt,name=task_name,json=taskName,proto3" json:"task_name,omitempty"`
+	TaskPayload   []byte                 `protobuf:"bytes,3,opt,name=task_payload,json=taskPayload,proto3" json:"task_payload,omitempty"`
+	Placement     *Placement             `protobuf:"bytes,4,opt,name=placement,proto3" json:"placement,omitempty"`                                                                     // Optional; restricts the workers the task may run on
+	Requests      *Resources             `protobuf:"bytes,5,opt,name=requests,proto3" json:"requests,omitempty"`                                                                       // Optional; resources reserved on the worker for the task
+	Labels        map[string]string      `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Optional; lets clients select the task later, e.g. to cancel it
+	TimeoutMillis int64                  `protobuf:"varint,7,opt,name=timeout_millis,json=timeoutMillis,proto3" json:"timeout_millis,omitempty"`                                       // Optional; execution time limit, 0 = none
+	RetryPolicy   *RetryPolicy           `protobuf:"bytes,8,opt,name=retry_policy,json=retryPolicy,proto3" json:"retry_policy,omitempty"`                                              // Optional; the master's default policy applies otherwise
+	// Optional; a submission repeating the key of a task submitted within the
+	// master's dedupe window creates nothing and returns that task instead
+	IdempotencyKey string `protobuf:"bytes,9,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SubmitTaskRequest) Reset() {
+	*x = SubmitTaskRequest{}
+	mi := &file_proto_scheduler_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitTaskRequest) ProtoMessage() {}
+
+func (x *SubmitTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitTaskRequest.ProtoReflect.Descriptor instead.
+func (*SubmitTaskRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SubmitTaskRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *SubmitTaskRequest) GetTaskName() string {
+	if x != nil {
+		return x.TaskName
+	}
+	return ""
+}
+
+func (x *SubmitTaskRequest) GetTaskPayload() []byte {
+	if x != nil {
+		return x.TaskPayload
+	}
+	return nil
+}
+
+func (x *SubmitTaskRequest) GetPlacement() *Placement {
+	if x != nil {
+		return x.Placement
+	}
+	return nil
+}
+
+func (x *SubmitTaskRequest) GetRequests() *Resources {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+func (x *SubmitTaskRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *SubmitTaskRequest) GetTimeoutMillis() int64 {
+	if x != nil {
+		return x.TimeoutMillis
+	}
+	return 0
+}
+
+func (x *SubmitTaskRequest) GetRetryPolicy() *RetryPolicy {
+	if x != nil {
+		return x.RetryPolicy
+	}
+	return nil
+}
+
+func (x *SubmitTaskRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type SubmitTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Duplicate     bool                   `protobuf:"varint,2,opt,name=duplicate,proto3" json:"duplicate,omitempty"`                  // The idempotency key matched an existing task
+	State         TaskState              `protobuf:"varint,3,opt,name=state,proto3,enum=scheduler.TaskState" json:"state,omitempty"` // Current state of the existing task when duplicate
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitTaskResponse) Reset() {
+	*x = SubmitTaskResponse{}
+	mi := &file_proto_scheduler_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitTaskResponse) ProtoMessage() {}
+
+func (x *SubmitTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitTaskResponse.ProtoReflect.Descriptor instead.
+func (*SubmitTaskResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SubmitTaskResponse) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *SubmitTaskResponse) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
+func (x *SubmitTaskResponse) GetState() TaskState {
+	if x != nil {
+		return x.State
+	}
+	return TaskState_TASK_STATE_UNSPECIFIED
+}
+
+type SubmitBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*SubmitTaskRequest   `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitBatchRequest) Reset() {
+	*x = SubmitBatchRequest{}
+	mi := &file_proto_scheduler_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitBatchRequest) ProtoMessage() {}
+
+func (x *SubmitBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitBatchRequest.ProtoReflect.Descriptor instead.
+func (*SubmitBatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SubmitBatchRequest) GetTasks() []*SubmitTaskRequest {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type SubmitBatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One entry per request task, in order
+	Results       []*SubmitBatchItem `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitBatchResponse) Reset() {
+	*x = SubmitBatchResponse{}
+	mi := &file_proto_scheduler_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitBatchResponse) ProtoMessage() {}
+
+func (x *SubmitBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitBatchResponse.ProtoReflect.Descriptor instead.
+func (*SubmitBatchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SubmitBatchResponse) GetResults() []*SubmitBatchItem {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type SubmitBatchItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`                           // Empty when the task was accepted
+	Duplicate     bool                   `protobuf:"varint,3,opt,name=duplicate,proto3" json:"duplicate,omitempty"`                  // As in SubmitTaskResponse
+	State         TaskState              `protobuf:"varint,4,opt,name=state,proto3,enum=scheduler.TaskState" json:"state,omitempty"` // As in SubmitTaskResponse
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitBatchItem) Reset() {
+	*x = SubmitBatchItem{}
+	mi := &file_proto_scheduler_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitBatchItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitBatchItem) ProtoMessage() {}
+
+func (x *SubmitBatchItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitBatchItem.ProtoReflect.Descriptor instead.
+func (*SubmitBatchItem) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *SubmitBatchItem) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *SubmitBatchItem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *SubmitBatchItem) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
+func (x *SubmitBatchItem) GetState() TaskState {
+	if x != nil {
+		return x.State
+	}
+	return TaskState_TASK_STATE_UNSPECIFIED
+}
+
+type TaskInfo struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	TaskId             string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	TaskName           string                 `protobuf:"bytes,2,opt,name=task_name,json=taskName,proto3" json:"task_name,omitempty"`
+	State              TaskState              `protobuf:"varint,3,opt,name=state,proto3,enum=scheduler.TaskState" json:"state,omitempty"`
+	WorkerId           string                 `protobuf:"bytes,4,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"` // Worker of the current/last attempt
+	Attempts           int32                  `protobuf:"varint,5,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	CreatedAtUnixNano  int64                  `protobuf:"varint,6,opt,name=created_at_unix_nano,json=createdAtUnixNano,proto3" json:"created_at_unix_nano,omitempty"`
+	AssignedAtUnixNano int64                  `protobuf:"varint,7,opt,name=assigned_at_unix_nano,json=assignedAtUnixNano,proto3" json:"assigned_at_unix_nano,omitempty"`
+	StartedAtUnixNano  int64                  `protobuf:"varint,8,opt,name=started_at_unix_nano,json=startedAtUnixNano,proto3" json:"started_at_unix_nano,omitempty"`
+	FinishedAtUnixNano int64                  `protobuf:"varint,9,opt,name=finished_at_unix_nano,json=finishedAtUnixNano,proto3" json:"finished_at_unix_nano,omitempty"`
+	LastError          string                 `protobuf:"bytes,10,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	Output             []byte                 `protobuf:"bytes,11,opt,name=output,proto3" json:"output,omitempty"`
+	Labels             map[string]string      `protobuf:"bytes,12,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	NotBeforeUnixNano  int64                  `protobuf:"varint,13,opt,name=not_before_unix_nano,json=notBeforeUnixNano,proto3" json:"not_before_unix_nano,omitempty"` // Set while a retry waits for its backoff
+	FencingToken       uint64                 `protobuf:"varint,14,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`                    // Token of the current/last attempt
+	IdempotencyKey     string                 `protobuf:"bytes,15,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *TaskInfo) Reset() {
+	*x = TaskInfo{}
+	mi := &file_proto_scheduler_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskInfo) ProtoMessage() {}
+
+func (x *TaskInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskInfo.ProtoReflect.Descriptor instead.
+func (*TaskInfo) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *TaskInfo) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskInfo) GetTaskName() string {
+	if x != nil {
+		return x.TaskName
+	}
+	return ""
+}
+
+func (x *TaskInfo) GetState() TaskState {
+	if x != nil {
+		return x.State
+	}
+	return TaskState_TASK_STATE_UNSPECIFIED
+}
+
+func (x *TaskInfo) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+func (x *TaskInfo) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *TaskInfo) GetCreatedAtUnixNano() int64 {
+	if x != nil {
+		return x.CreatedAtUnixNano
+	}
+	return 0
+}
+
+func (x *TaskInfo) GetAssignedAtUnixNano() int64 {
+	if x != nil {
+		return x.AssignedAtUnixNano
+	}
+	return 0
+}
+
+func (x *TaskInfo) GetStartedAtUnixNano() int64 {
+	if x != nil {
+		return x.StartedAtUnixNano
+	}
+	return 0
+}
+
+func (x *TaskInfo) GetFinishedAtUnixNano() int64 {
+	if x != nil {
+		return x.FinishedAtUnixNano
+	}
+	return 0
+}
+
+func (x *TaskInfo) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *TaskInfo) GetOutput() []byte {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *TaskInfo) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *TaskInfo) GetNotBeforeUnixNano() int64 {
+	if x != nil {
+		return x.NotBeforeUnixNano
+	}
+	return 0
+}
+
+func (x *TaskInfo) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
+func (x *TaskInfo) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type GetTaskStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTaskStatusRequest) Reset() {
+	*x = GetTaskStatusRequest{}
+	mi := &file_proto_scheduler_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTaskStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTaskStatusRequest) ProtoMessage() {}
+
+func (x *GetTaskStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTaskStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetTaskStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetTaskStatusRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type WaitForTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WaitForTaskRequest) Reset() {
+	*x = WaitForTaskRequest{}
+	mi := &file_proto_scheduler_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaitForTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitForTaskRequest) ProtoMessage() {}
+
+func (x *WaitForTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaitForTaskRequest.ProtoReflect.Descriptor instead.
+func (*WaitForTaskRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *WaitForTaskRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type CancelTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"` // Optional; recorded as the task's last_error
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTaskRequest) Reset() {
+	*x = CancelTaskRequest{}
+	mi := &file_proto_scheduler_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTaskRequest) ProtoMessage() {}
+
+func (x *CancelTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTaskRequest.ProtoReflect.Descriptor instead.
+func (*CancelTaskRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *CancelTaskRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *CancelTaskRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type CancelTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          *TaskInfo              `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"` // State after cancellation
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTaskResponse) Reset() {
+	*x = CancelTaskResponse{}
+	mi := &file_proto_scheduler_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTaskResponse) ProtoMessage() {}
+
+func (x *CancelTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTaskResponse.ProtoReflect.Descriptor instead.
+func (*CancelTaskResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *CancelTaskResponse) GetTask() *TaskInfo {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+// Unfinished tasks matching every set field are cancelled; at least one
+// field must be set
+type CancelTasksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Labels        map[string]string      `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // every key must have exactly this value
+	TaskName      string                 `protobuf:"bytes,2,opt,name=task_name,json=taskName,proto3" json:"task_name,omitempty"`
+	WorkerId      string                 `protobuf:"bytes,3,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTasksRequest) Reset() {
+	*x = CancelTasksRequest{}
+	mi := &file_proto_scheduler_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTasksRequest) ProtoMessage() {}
+
+func (x *CancelTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTasksRequest.ProtoReflect.Descriptor instead.
+func (*CancelTasksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *CancelTasksRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *CancelTasksRequest) GetTaskName() string {
+	if x != nil {
+		return x.TaskName
+	}
+	return ""
+}
+
+func (x *CancelTasksRequest) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+func (x *CancelTasksRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type CancelTasksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*TaskInfo            `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"` // The cancelled tasks, in their state after cancellation
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTasksResponse) Reset() {
+	*x = CancelTasksResponse{}
+	mi := &file_proto_scheduler_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTasksResponse) ProtoMessage() {}
+
+func (x *CancelTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTasksResponse.ProtoReflect.Descriptor instead.
+func (*CancelTasksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *CancelTasksResponse) GetTasks() []*TaskInfo {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+// --- Operator <-> Master (AdminService) ---
+type DrainWorkerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkerId      string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	TimeoutMillis int64                  `protobuf:"varint,2,opt,name=timeout_millis,json=timeoutMillis,proto3" json:"timeout_millis,omitempty"` // 0 = worker default
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DrainWorkerRequest) Reset() {
+	*x = DrainWorkerRequest{}
+	mi := &file_proto_scheduler_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrainWorkerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainWorkerRequest) ProtoMessage() {}
+
+func (x *DrainWorkerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainWorkerRequest.ProtoReflect.Descriptor instead.
+func (*DrainWorkerRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *DrainWorkerRequest) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+func (x *DrainWorkerRequest) GetTimeoutMillis() int64 {
+	if x != nil {
+		return x.TimeoutMillis
+	}
+	return 0
+}
+
+func (x *DrainWorkerRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type DrainWorkerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	InFlight      int32                  `protobuf:"varint,1,opt,name=in_flight,json=inFlight,proto3" json:"in_flight,omitempty"` // Tasks the worker held when the drain started
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DrainWorkerResponse) Reset() {
+	*x = DrainWorkerResponse{}
+	mi := &file_proto_scheduler_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrainWorkerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainWorkerResponse) ProtoMessage() {}
+
+func (x *DrainWorkerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainWorkerResponse.ProtoReflect.Descriptor instead.
+func (*DrainWorkerResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *DrainWorkerResponse) GetInFlight() int32 {
+	if x != nil {
+		return x.InFlight
+	}
+	return 0
+}
+
+type DeadLetter struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Task           *TaskInfo              `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`             // Final state of the task
+	Assignment     *TaskAssignment        `protobuf:"bytes,2,opt,name=assignment,proto3" json:"assignment,omitempty"` // What was dispatched on the last attempt
+	Failures       []*TaskFailure         `protobuf:"bytes,3,rep,name=failures,proto3" json:"failures,omitempty"`     // Every failed attempt, oldest first
+	Reason         string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`         // Why it was not retried
+	DeadAtUnixNano int64                  `protobuf:"varint,5,opt,name=dead_at_unix_nano,json=deadAtUnixNano,proto3" json:"dead_at_unix_nano,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *RegisterResponse) GetMessage() string {
+func (x *DeadLetter) Reset() {
+	*x = DeadLetter{}
+	mi := &file_proto_scheduler_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeadLetter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadLetter) ProtoMessage() {}
+
+func (x *DeadLetter) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[37]
 	if x != nil {
-		return x.Message
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadLetter.ProtoReflect.Descriptor instead.
+func (*DeadLetter) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *DeadLetter) GetTask() *TaskInfo {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *DeadLetter) GetAssignment() *TaskAssignment {
+	if x != nil {
+		return x.Assignment
+	}
+	return nil
+}
+
+func (x *DeadLetter) GetFailures() []*TaskFailure {
+	if x != nil {
+		return x.Failures
+	}
+	return nil
+}
+
+func (x *DeadLetter) GetReason() string {
+	if x != nil {
+		return x.Reason
 	}
 	return ""
 }
 
-type TaskAssignment struct {
+func (x *DeadLetter) GetDeadAtUnixNano() int64 {
+	if x != nil {
+		return x.DeadAtUnixNano
+	}
+	return 0
+}
+
+type TaskFailure struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	TaskName      string                 `protobuf:"bytes,2,opt,name=task_name,json=taskName,proto3" json:"task_name,omitempty"`
-	TaskPayload   []byte                 `protobuf:"bytes,3,opt,name=task_payload,json=taskPayload,proto3" json:"task_payload,omitempty"` // The serialized task arguments
+	Attempt       int32                  `protobuf:"varint,1,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	WorkerId      string                 `protobuf:"bytes,2,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	ErrorClass    ErrorClass             `protobuf:"varint,4,opt,name=error_class,json=errorClass,proto3,enum=scheduler.ErrorClass" json:"error_class,omitempty"`
+	AtUnixNano    int64                  `protobuf:"varint,5,opt,name=at_unix_nano,json=atUnixNano,proto3" json:"at_unix_nano,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TaskAssignment) Reset() {
-	*x = TaskAssignment{}
-	mi := &file_proto_scheduler_proto_msgTypes[5]
+func (x *TaskFailure) Reset() {
+	*x = TaskFailure{}
+	mi := &file_proto_scheduler_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskAssignment) String() string {
+func (x *TaskFailure) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TaskAssignment) ProtoMessage() {}
+func (*TaskFailure) ProtoMessage() {}
 
-func (x *TaskAssignment) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_scheduler_proto_msgTypes[5]
+func (x *TaskFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -377,60 +3028,598 @@ func (x *TaskAssignment) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TaskAssignment.ProtoReflect.Descriptor instead.
-func (*TaskAssignment) Descriptor() ([]byte, []int) {
-	return file_proto_scheduler_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use TaskFailure.ProtoReflect.Descriptor instead.
+func (*TaskFailure) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *TaskAssignment) GetTaskId() string {
+func (x *TaskFailure) GetAttempt() int32 {
 	if x != nil {
-		return x.TaskId
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *TaskFailure) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
 	}
 	return ""
 }
 
-func (x *TaskAssignment) GetTaskName() string {
+func (x *TaskFailure) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *TaskFailure) GetErrorClass() ErrorClass {
+	if x != nil {
+		return x.ErrorClass
+	}
+	return ErrorClass_ERROR_CLASS_UNSPECIFIED
+}
+
+func (x *TaskFailure) GetAtUnixNano() int64 {
+	if x != nil {
+		return x.AtUnixNano
+	}
+	return 0
+}
+
+// Dead letters matching every set field, oldest first
+type ListDeadLettersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskName      string                 `protobuf:"bytes,1,opt,name=task_name,json=taskName,proto3" json:"task_name,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"` // 0 = no limit
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeadLettersRequest) Reset() {
+	*x = ListDeadLettersRequest{}
+	mi := &file_proto_scheduler_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeadLettersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeadLettersRequest) ProtoMessage() {}
+
+func (x *ListDeadLettersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeadLettersRequest.ProtoReflect.Descriptor instead.
+func (*ListDeadLettersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ListDeadLettersRequest) GetTaskName() string {
 	if x != nil {
 		return x.TaskName
 	}
 	return ""
 }
 
-func (x *TaskAssignment) GetTaskPayload() []byte {
+func (x *ListDeadLettersRequest) GetLabels() map[string]string {
 	if x != nil {
-		return x.TaskPayload
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *ListDeadLettersRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListDeadLettersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeadLetters   []*DeadLetter          `protobuf:"bytes,1,rep,name=dead_letters,json=deadLetters,proto3" json:"dead_letters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeadLettersResponse) Reset() {
+	*x = ListDeadLettersResponse{}
+	mi := &file_proto_scheduler_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeadLettersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeadLettersResponse) ProtoMessage() {}
+
+func (x *ListDeadLettersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeadLettersResponse.ProtoReflect.Descriptor instead.
+func (*ListDeadLettersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ListDeadLettersResponse) GetDeadLetters() []*DeadLetter {
+	if x != nil {
+		return x.DeadLetters
 	}
 	return nil
 }
 
+type GetDeadLetterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeadLetterRequest) Reset() {
+	*x = GetDeadLetterRequest{}
+	mi := &file_proto_scheduler_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeadLetterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeadLetterRequest) ProtoMessage() {}
+
+func (x *GetDeadLetterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeadLetterRequest.ProtoReflect.Descriptor instead.
+func (*GetDeadLetterRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *GetDeadLetterRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type ReplayDeadLetterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	NewTaskId     string                 `protobuf:"bytes,2,opt,name=new_task_id,json=newTaskId,proto3" json:"new_task_id,omitempty"` // Optional; the master generates one when empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayDeadLetterRequest) Reset() {
+	*x = ReplayDeadLetterRequest{}
+	mi := &file_proto_scheduler_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayDeadLetterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayDeadLetterRequest) ProtoMessage() {}
+
+func (x *ReplayDeadLetterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayDeadLetterRequest.ProtoReflect.Descriptor instead.
+func (*ReplayDeadLetterRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ReplayDeadLetterRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *ReplayDeadLetterRequest) GetNewTaskId() string {
+	if x != nil {
+		return x.NewTaskId
+	}
+	return ""
+}
+
+type ReplayDeadLetterResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"` // ID of the new task
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayDeadLetterResponse) Reset() {
+	*x = ReplayDeadLetterResponse{}
+	mi := &file_proto_scheduler_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayDeadLetterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayDeadLetterResponse) ProtoMessage() {}
+
+func (x *ReplayDeadLetterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scheduler_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayDeadLetterResponse.ProtoReflect.Descriptor instead.
+func (*ReplayDeadLetterResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scheduler_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ReplayDeadLetterResponse) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
 var File_proto_scheduler_proto protoreflect.FileDescriptor
 
 const file_proto_scheduler_proto_rawDesc = "" +
 	"\n" +
-	"\x15proto/scheduler.proto\x12\tscheduler\"\xc0\x01\n" +
+	"\x15proto/scheduler.proto\x12\tscheduler\"\x97\x04\n" +
 	"\rWorkerMessage\x12\x1b\n" +
 	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12G\n" +
 	"\x10register_request\x18\x02 \x01(\v2\x1a.scheduler.RegisterRequestH\x00R\x0fregisterRequest\x12>\n" +
-	"\rstatus_update\x18\x03 \x01(\v2\x17.scheduler.StatusUpdateH\x00R\fstatusUpdateB\t\n" +
-	"\apayload\"V\n" +
+	"\rstatus_update\x18\x03 \x01(\v2\x17.scheduler.StatusUpdateH\x00R\fstatusUpdate\x128\n" +
+	"\vtask_result\x18\x04 \x01(\v2\x15.scheduler.TaskResultH\x00R\n" +
+	"taskResult\x12%\n" +
+	"\x04pong\x18\x05 \x01(\v2\x0f.scheduler.PongH\x00R\x04pong\x12;\n" +
+	"\ftask_started\x18\x06 \x01(\v2\x16.scheduler.TaskStartedH\x00R\vtaskStarted\x12>\n" +
+	"\rdrain_request\x18\a \x01(\v2\x17.scheduler.DrainRequestH\x00R\fdrainRequest\x127\n" +
+	"\n" +
+	"deregister\x18\b \x01(\v2\x15.scheduler.DeregisterH\x00R\n" +
+	"deregister\x12>\n" +
+	"\rtask_accepted\x18\t \x01(\v2\x17.scheduler.TaskAcceptedH\x00R\ftaskAcceptedB\t\n" +
+	"\apayload\"\xd5\x02\n" +
 	"\x0fRegisterRequest\x12\x1a\n" +
 	"\bhostname\x18\x01 \x01(\tR\bhostname\x12'\n" +
-	"\x0fmax_concurrency\x18\x02 \x01(\x05R\x0emaxConcurrency\":\n" +
+	"\x0fmax_concurrency\x18\x02 \x01(\x05R\x0emaxConcurrency\x12>\n" +
+	"\x06labels\x18\x03 \x03(\v2&.scheduler.RegisterRequest.LabelsEntryR\x06labels\x120\n" +
+	"\bcapacity\x18\x04 \x01(\v2\x14.scheduler.ResourcesR\bcapacity\x124\n" +
+	"\tin_flight\x18\x05 \x03(\v2\x17.scheduler.InFlightTaskR\binFlight\x12\x1a\n" +
+	"\bdraining\x18\x06 \x01(\bR\bdraining\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x88\x01\n" +
+	"\fInFlightTask\x12-\n" +
+	"\x04task\x18\x01 \x01(\v2\x19.scheduler.TaskAssignmentR\x04task\x12\x18\n" +
+	"\astarted\x18\x02 \x01(\bR\astarted\x12/\n" +
+	"\x14start_time_unix_nano\x18\x03 \x01(\x03R\x11startTimeUnixNano\"\xbe\x01\n" +
+	"\tResources\x12\x1d\n" +
+	"\n" +
+	"cpu_millis\x18\x01 \x01(\x03R\tcpuMillis\x12!\n" +
+	"\fmemory_bytes\x18\x02 \x01(\x03R\vmemoryBytes\x125\n" +
+	"\x05named\x18\x03 \x03(\v2\x1f.scheduler.Resources.NamedEntryR\x05named\x1a8\n" +
+	"\n" +
+	"NamedEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\xbb\x03\n" +
 	"\fStatusUpdate\x12*\n" +
-	"\x11active_task_count\x18\x01 \x01(\x05R\x0factiveTaskCount\"\xac\x01\n" +
+	"\x11active_task_count\x18\x01 \x01(\x05R\x0factiveTaskCount\x12*\n" +
+	"\x11queued_task_count\x18\x02 \x01(\x05R\x0fqueuedTaskCount\x12\x1a\n" +
+	"\bperiodic\x18\x03 \x01(\bR\bperiodic\x120\n" +
+	"\ametrics\x18\x04 \x01(\v2\x16.scheduler.NodeMetricsR\ametrics\x121\n" +
+	"\alatency\x18\x05 \x01(\v2\x17.scheduler.LatencyStatsR\alatency\x12K\n" +
+	"\ftask_latency\x18\x06 \x03(\v2(.scheduler.StatusUpdate.TaskLatencyEntryR\vtaskLatency\x12,\n" +
+	"\x06leases\x18\a \x03(\v2\x14.scheduler.TaskLeaseR\x06leases\x1aW\n" +
+	"\x10TaskLatencyEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12-\n" +
+	"\x05value\x18\x02 \x01(\v2\x17.scheduler.LatencyStatsR\x05value:\x028\x01\"\x8e\x03\n" +
+	"\vNodeMetrics\x12\x1b\n" +
+	"\tcpu_usage\x18\x01 \x01(\x01R\bcpuUsage\x12\x19\n" +
+	"\bnum_cpus\x18\x02 \x01(\x05R\anumCpus\x12&\n" +
+	"\x0fmem_total_bytes\x18\x03 \x01(\x03R\rmemTotalBytes\x12.\n" +
+	"\x13mem_available_bytes\x18\x04 \x01(\x03R\x11memAvailableBytes\x12\x14\n" +
+	"\x05load1\x18\x05 \x01(\x01R\x05load1\x12\x14\n" +
+	"\x05load5\x18\x06 \x01(\x01R\x05load5\x12\x16\n" +
+	"\x06load15\x18\a \x01(\x01R\x06load15\x125\n" +
+	"\x17cgroup_cpu_limit_millis\x18\b \x01(\x03R\x14cgroupCpuLimitMillis\x129\n" +
+	"\x19cgroup_memory_limit_bytes\x18\t \x01(\x03R\x16cgroupMemoryLimitBytes\x129\n" +
+	"\x19cgroup_memory_usage_bytes\x18\n" +
+	" \x01(\x03R\x16cgroupMemoryUsageBytes\"b\n" +
+	"\fLatencyStats\x12\x1b\n" +
+	"\tp50_nanos\x18\x01 \x01(\x03R\bp50Nanos\x12\x1b\n" +
+	"\tp99_nanos\x18\x02 \x01(\x03R\bp99Nanos\x12\x18\n" +
+	"\asamples\x18\x03 \x01(\x05R\asamples\"|\n" +
+	"\vTaskStarted\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12/\n" +
+	"\x14start_time_unix_nano\x18\x02 \x01(\x03R\x11startTimeUnixNano\x12#\n" +
+	"\rfencing_token\x18\x03 \x01(\x04R\ffencingToken\"L\n" +
+	"\fTaskAccepted\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12#\n" +
+	"\rfencing_token\x18\x02 \x01(\x04R\ffencingToken\"I\n" +
+	"\tTaskLease\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12#\n" +
+	"\rfencing_token\x18\x02 \x01(\x04R\ffencingToken\"\xbd\x02\n" +
+	"\n" +
+	"TaskResult\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12-\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x15.scheduler.TaskStatusR\x06status\x12\x16\n" +
+	"\x06output\x18\x03 \x01(\fR\x06output\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12/\n" +
+	"\x14start_time_unix_nano\x18\x05 \x01(\x03R\x11startTimeUnixNano\x12+\n" +
+	"\x12end_time_unix_nano\x18\x06 \x01(\x03R\x0fendTimeUnixNano\x126\n" +
+	"\verror_class\x18\a \x01(\x0e2\x15.scheduler.ErrorClassR\n" +
+	"errorClass\x12#\n" +
+	"\rfencing_token\x18\b \x01(\x04R\ffencingToken\"&\n" +
+	"\fDrainRequest\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\"T\n" +
+	"\n" +
+	"Deregister\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\x12.\n" +
+	"\x13unfinished_task_ids\x18\x02 \x03(\tR\x11unfinishedTaskIds\"y\n" +
+	"\x04Pong\x12\x14\n" +
+	"\x05nonce\x18\x01 \x01(\x04R\x05nonce\x12-\n" +
+	"\x13ping_sent_unix_nano\x18\x02 \x01(\x03R\x10pingSentUnixNano\x12,\n" +
+	"\x12received_unix_nano\x18\x03 \x01(\x03R\x10receivedUnixNano\"\xb7\x02\n" +
 	"\rMasterMessage\x12J\n" +
 	"\x11register_response\x18\x01 \x01(\v2\x1b.scheduler.RegisterResponseH\x00R\x10registerResponse\x12D\n" +
-	"\x0ftask_assignment\x18\x02 \x01(\v2\x19.scheduler.TaskAssignmentH\x00R\x0etaskAssignmentB\t\n" +
-	"\apayload\"F\n" +
+	"\x0ftask_assignment\x18\x02 \x01(\v2\x19.scheduler.TaskAssignmentH\x00R\x0etaskAssignment\x12%\n" +
+	"\x04ping\x18\x03 \x01(\v2\x0f.scheduler.PingH\x00R\x04ping\x12(\n" +
+	"\x05drain\x18\x04 \x01(\v2\x10.scheduler.DrainH\x00R\x05drain\x128\n" +
+	"\vcancel_task\x18\x05 \x01(\v2\x15.scheduler.CancelTaskH\x00R\n" +
+	"cancelTaskB\t\n" +
+	"\apayload\"b\n" +
+	"\n" +
+	"CancelTask\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12#\n" +
+	"\rfencing_token\x18\x03 \x01(\x04R\ffencingToken\"F\n" +
+	"\x05Drain\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\x12%\n" +
+	"\x0etimeout_millis\x18\x02 \x01(\x03R\rtimeoutMillis\"F\n" +
 	"\x10RegisterResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"i\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x93\x04\n" +
 	"\x0eTaskAssignment\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x1b\n" +
 	"\ttask_name\x18\x02 \x01(\tR\btaskName\x12!\n" +
-	"\ftask_payload\x18\x03 \x01(\fR\vtaskPayload2U\n" +
+	"\ftask_payload\x18\x03 \x01(\fR\vtaskPayload\x12\x18\n" +
+	"\aattempt\x18\x04 \x01(\x05R\aattempt\x122\n" +
+	"\tplacement\x18\x05 \x01(\v2\x14.scheduler.PlacementR\tplacement\x120\n" +
+	"\brequests\x18\x06 \x01(\v2\x14.scheduler.ResourcesR\brequests\x12=\n" +
+	"\x06labels\x18\a \x03(\v2%.scheduler.TaskAssignment.LabelsEntryR\x06labels\x12%\n" +
+	"\x0etimeout_millis\x18\b \x01(\x03R\rtimeoutMillis\x129\n" +
+	"\fretry_policy\x18\t \x01(\v2\x16.scheduler.RetryPolicyR\vretryPolicy\x12#\n" +
+	"\rfencing_token\x18\n" +
+	" \x01(\x04R\ffencingToken\x12'\n" +
+	"\x0fidempotency_key\x18\v \x01(\tR\x0eidempotencyKey\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd8\x01\n" +
+	"\vRetryPolicy\x12!\n" +
+	"\fmax_attempts\x18\x01 \x01(\x05R\vmaxAttempts\x12.\n" +
+	"\x13backoff_base_millis\x18\x02 \x01(\x03R\x11backoffBaseMillis\x12,\n" +
+	"\x12backoff_max_millis\x18\x03 \x01(\x03R\x10backoffMaxMillis\x12\x16\n" +
+	"\x06jitter\x18\x04 \x01(\x01R\x06jitter\x120\n" +
+	"\bretry_on\x18\x05 \x03(\x0e2\x15.scheduler.ErrorClassR\aretryOn\"\x92\x02\n" +
+	"\tPlacement\x12K\n" +
+	"\rnode_selector\x18\x01 \x03(\v2&.scheduler.Placement.NodeSelectorEntryR\fnodeSelector\x126\n" +
+	"\baffinity\x18\x02 \x03(\v2\x1a.scheduler.LabelExpressionR\baffinity\x12?\n" +
+	"\ranti_affinity\x18\x03 \x03(\v2\x1a.scheduler.LabelExpressionR\fantiAffinity\x1a?\n" +
+	"\x11NodeSelectorEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"q\n" +
+	"\x0fLabelExpression\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x124\n" +
+	"\boperator\x18\x02 \x01(\x0e2\x18.scheduler.LabelOperatorR\boperator\x12\x16\n" +
+	"\x06values\x18\x03 \x03(\tR\x06values\"B\n" +
+	"\x04Ping\x12\x14\n" +
+	"\x05nonce\x18\x01 \x01(\x04R\x05nonce\x12$\n" +
+	"\x0esent_unix_nano\x18\x02 \x01(\x03R\fsentUnixNano\"\xda\x03\n" +
+	"\x11SubmitTaskRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x1b\n" +
+	"\ttask_name\x18\x02 \x01(\tR\btaskName\x12!\n" +
+	"\ftask_payload\x18\x03 \x01(\fR\vtaskPayload\x122\n" +
+	"\tplacement\x18\x04 \x01(\v2\x14.scheduler.PlacementR\tplacement\x120\n" +
+	"\brequests\x18\x05 \x01(\v2\x14.scheduler.ResourcesR\brequests\x12@\n" +
+	"\x06labels\x18\x06 \x03(\v2(.scheduler.SubmitTaskRequest.LabelsEntryR\x06labels\x12%\n" +
+	"\x0etimeout_millis\x18\a \x01(\x03R\rtimeoutMillis\x129\n" +
+	"\fretry_policy\x18\b \x01(\v2\x16.scheduler.RetryPolicyR\vretryPolicy\x12'\n" +
+	"\x0fidempotency_key\x18\t \x01(\tR\x0eidempotencyKey\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"w\n" +
+	"\x12SubmitTaskResponse\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x1c\n" +
+	"\tduplicate\x18\x02 \x01(\bR\tduplicate\x12*\n" +
+	"\x05state\x18\x03 \x01(\x0e2\x14.scheduler.TaskStateR\x05state\"H\n" +
+	"\x12SubmitBatchRequest\x122\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x1c.scheduler.SubmitTaskRequestR\x05tasks\"K\n" +
+	"\x13SubmitBatchResponse\x124\n" +
+	"\aresults\x18\x01 \x03(\v2\x1a.scheduler.SubmitBatchItemR\aresults\"\x8a\x01\n" +
+	"\x0fSubmitBatchItem\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1c\n" +
+	"\tduplicate\x18\x03 \x01(\bR\tduplicate\x12*\n" +
+	"\x05state\x18\x04 \x01(\x0e2\x14.scheduler.TaskStateR\x05state\"\x97\x05\n" +
+	"\bTaskInfo\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x1b\n" +
+	"\ttask_name\x18\x02 \x01(\tR\btaskName\x12*\n" +
+	"\x05state\x18\x03 \x01(\x0e2\x14.scheduler.TaskStateR\x05state\x12\x1b\n" +
+	"\tworker_id\x18\x04 \x01(\tR\bworkerId\x12\x1a\n" +
+	"\battempts\x18\x05 \x01(\x05R\battempts\x12/\n" +
+	"\x14created_at_unix_nano\x18\x06 \x01(\x03R\x11createdAtUnixNano\x121\n" +
+	"\x15assigned_at_unix_nano\x18\a \x01(\x03R\x12assignedAtUnixNano\x12/\n" +
+	"\x14started_at_unix_nano\x18\b \x01(\x03R\x11startedAtUnixNano\x121\n" +
+	"\x15finished_at_unix_nano\x18\t \x01(\x03R\x12finishedAtUnixNano\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\n" +
+	" \x01(\tR\tlastError\x12\x16\n" +
+	"\x06output\x18\v \x01(\fR\x06output\x127\n" +
+	"\x06labels\x18\f \x03(\v2\x1f.scheduler.TaskInfo.LabelsEntryR\x06labels\x12/\n" +
+	"\x14not_before_unix_nano\x18\r \x01(\x03R\x11notBeforeUnixNano\x12#\n" +
+	"\rfencing_token\x18\x0e \x01(\x04R\ffencingToken\x12'\n" +
+	"\x0fidempotency_key\x18\x0f \x01(\tR\x0eidempotencyKey\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"/\n" +
+	"\x14GetTaskStatusRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"-\n" +
+	"\x12WaitForTaskRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"D\n" +
+	"\x11CancelTaskRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"=\n" +
+	"\x12CancelTaskResponse\x12'\n" +
+	"\x04task\x18\x01 \x01(\v2\x13.scheduler.TaskInfoR\x04task\"\xe4\x01\n" +
+	"\x12CancelTasksRequest\x12A\n" +
+	"\x06labels\x18\x01 \x03(\v2).scheduler.CancelTasksRequest.LabelsEntryR\x06labels\x12\x1b\n" +
+	"\ttask_name\x18\x02 \x01(\tR\btaskName\x12\x1b\n" +
+	"\tworker_id\x18\x03 \x01(\tR\bworkerId\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"@\n" +
+	"\x13CancelTasksResponse\x12)\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x13.scheduler.TaskInfoR\x05tasks\"p\n" +
+	"\x12DrainWorkerRequest\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12%\n" +
+	"\x0etimeout_millis\x18\x02 \x01(\x03R\rtimeoutMillis\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"2\n" +
+	"\x13DrainWorkerResponse\x12\x1b\n" +
+	"\tin_flight\x18\x01 \x01(\x05R\binFlight\"\xe7\x01\n" +
+	"\n" +
+	"DeadLetter\x12'\n" +
+	"\x04task\x18\x01 \x01(\v2\x13.scheduler.TaskInfoR\x04task\x129\n" +
+	"\n" +
+	"assignment\x18\x02 \x01(\v2\x19.scheduler.TaskAssignmentR\n" +
+	"assignment\x122\n" +
+	"\bfailures\x18\x03 \x03(\v2\x16.scheduler.TaskFailureR\bfailures\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12)\n" +
+	"\x11dead_at_unix_nano\x18\x05 \x01(\x03R\x0edeadAtUnixNano\"\xb4\x01\n" +
+	"\vTaskFailure\x12\x18\n" +
+	"\aattempt\x18\x01 \x01(\x05R\aattempt\x12\x1b\n" +
+	"\tworker_id\x18\x02 \x01(\tR\bworkerId\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x126\n" +
+	"\verror_class\x18\x04 \x01(\x0e2\x15.scheduler.ErrorClassR\n" +
+	"errorClass\x12 \n" +
+	"\fat_unix_nano\x18\x05 \x01(\x03R\n" +
+	"atUnixNano\"\xcd\x01\n" +
+	"\x16ListDeadLettersRequest\x12\x1b\n" +
+	"\ttask_name\x18\x01 \x01(\tR\btaskName\x12E\n" +
+	"\x06labels\x18\x02 \x03(\v2-.scheduler.ListDeadLettersRequest.LabelsEntryR\x06labels\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"S\n" +
+	"\x17ListDeadLettersResponse\x128\n" +
+	"\fdead_letters\x18\x01 \x03(\v2\x15.scheduler.DeadLetterR\vdeadLetters\"/\n" +
+	"\x14GetDeadLetterRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"R\n" +
+	"\x17ReplayDeadLetterRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x1e\n" +
+	"\vnew_task_id\x18\x02 \x01(\tR\tnewTaskId\"3\n" +
+	"\x18ReplayDeadLetterResponse\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId*\x92\x01\n" +
+	"\n" +
+	"TaskStatus\x12\x1b\n" +
+	"\x17TASK_STATUS_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15TASK_STATUS_SUCCEEDED\x10\x01\x12\x16\n" +
+	"\x12TASK_STATUS_FAILED\x10\x02\x12\x19\n" +
+	"\x15TASK_STATUS_CANCELLED\x10\x03\x12\x19\n" +
+	"\x15TASK_STATUS_TIMED_OUT\x10\x04*\xc5\x01\n" +
+	"\n" +
+	"ErrorClass\x12\x1b\n" +
+	"\x17ERROR_CLASS_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13ERROR_CLASS_HANDLER\x10\x01\x12\x19\n" +
+	"\x15ERROR_CLASS_PERMANENT\x10\x02\x12\x17\n" +
+	"\x13ERROR_CLASS_TIMEOUT\x10\x03\x12\x1c\n" +
+	"\x18ERROR_CLASS_UNKNOWN_TASK\x10\x04\x12\x15\n" +
+	"\x11ERROR_CLASS_PANIC\x10\x05\x12\x18\n" +
+	"\x14ERROR_CLASS_REJECTED\x10\x06*\x9f\x01\n" +
+	"\rLabelOperator\x12\x1e\n" +
+	"\x1aLABEL_OPERATOR_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11LABEL_OPERATOR_IN\x10\x01\x12\x19\n" +
+	"\x15LABEL_OPERATOR_NOT_IN\x10\x02\x12\x19\n" +
+	"\x15LABEL_OPERATOR_EXISTS\x10\x03\x12!\n" +
+	"\x1dLABEL_OPERATOR_DOES_NOT_EXIST\x10\x04*\xd5\x01\n" +
+	"\tTaskState\x12\x1a\n" +
+	"\x16TASK_STATE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12TASK_STATE_PENDING\x10\x01\x12\x17\n" +
+	"\x13TASK_STATE_ASSIGNED\x10\x02\x12\x16\n" +
+	"\x12TASK_STATE_RUNNING\x10\x03\x12\x18\n" +
+	"\x14TASK_STATE_SUCCEEDED\x10\x04\x12\x15\n" +
+	"\x11TASK_STATE_FAILED\x10\x05\x12\x18\n" +
+	"\x14TASK_STATE_CANCELLED\x10\x06\x12\x18\n" +
+	"\x14TASK_STATE_TIMED_OUT\x10\a2U\n" +
 	"\x10SchedulerService\x12A\n" +
-	"\aConnect\x12\x18.scheduler.WorkerMessage\x1a\x18.scheduler.MasterMessage(\x010\x01B0Z.github.com/YilinZhang0101/SwiftScheduler/protob\x06proto3"
+	"\aConnect\x12\x18.scheduler.WorkerMessage\x1a\x18.scheduler.MasterMessage(\x010\x012\xcb\x03\n" +
+	"\vTaskService\x12I\n" +
+	"\n" +
+	"SubmitTask\x12\x1c.scheduler.SubmitTaskRequest\x1a\x1d.scheduler.SubmitTaskResponse\x12L\n" +
+	"\vSubmitBatch\x12\x1d.scheduler.SubmitBatchRequest\x1a\x1e.scheduler.SubmitBatchResponse\x12E\n" +
+	"\rGetTaskStatus\x12\x1f.scheduler.GetTaskStatusRequest\x1a\x13.scheduler.TaskInfo\x12C\n" +
+	"\vWaitForTask\x12\x1d.scheduler.WaitForTaskRequest\x1a\x13.scheduler.TaskInfo0\x01\x12I\n" +
+	"\n" +
+	"CancelTask\x12\x1c.scheduler.CancelTaskRequest\x1a\x1d.scheduler.CancelTaskResponse\x12L\n" +
+	"\vCancelTasks\x12\x1d.scheduler.CancelTasksRequest\x1a\x1e.scheduler.CancelTasksResponse2\xdc\x02\n" +
+	"\fAdminService\x12L\n" +
+	"\vDrainWorker\x12\x1d.scheduler.DrainWorkerRequest\x1a\x1e.scheduler.DrainWorkerResponse\x12X\n" +
+	"\x0fListDeadLetters\x12!.scheduler.ListDeadLettersRequest\x1a\".scheduler.ListDeadLettersResponse\x12G\n" +
+	"\rGetDeadLetter\x12\x1f.scheduler.GetDeadLetterRequest\x1a\x15.scheduler.DeadLetter\x12[\n" +
+	"\x10ReplayDeadLetter\x12\".scheduler.ReplayDeadLetterRequest\x1a#.scheduler.ReplayDeadLetterResponseB0Z.github.com/YilinZhang0101/SwiftScheduler/protob\x06proto3"
 
 var (
 	file_proto_scheduler_proto_rawDescOnce sync.Once
@@ -444,27 +3633,148 @@ func file_proto_scheduler_proto_rawDescGZIP() []byte {
 	return file_proto_scheduler_proto_rawDescData
 }
 
-var file_proto_scheduler_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proto_scheduler_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_proto_scheduler_proto_msgTypes = make([]protoimpl.MessageInfo, 53)
 var file_proto_scheduler_proto_goTypes = []any{
-	(*WorkerMessage)(nil),    // 0: scheduler.WorkerMessage
-	(*RegisterRequest)(nil),  // 1: scheduler.RegisterRequest
-	(*StatusUpdate)(nil),     // 2: scheduler.StatusUpdate
-	(*MasterMessage)(nil),    // 3: scheduler.MasterMessage
-	(*RegisterResponse)(nil), // 4: scheduler.RegisterResponse
-	(*TaskAssignment)(nil),   // 5: scheduler.TaskAssignment
+	(TaskStatus)(0),                  // 0: scheduler.TaskStatus
+	(ErrorClass)(0),                  // 1: scheduler.ErrorClass
+	(LabelOperator)(0),               // 2: scheduler.LabelOperator
+	(TaskState)(0),                   // 3: scheduler.TaskState
+	(*WorkerMessage)(nil),            // 4: scheduler.WorkerMessage
+	(*RegisterRequest)(nil),          // 5: scheduler.RegisterRequest
+	(*InFlightTask)(nil),             // 6: scheduler.InFlightTask
+	(*Resources)(nil),                // 7: scheduler.Resources
+	(*StatusUpdate)(nil),             // 8: scheduler.StatusUpdate
+	(*NodeMetrics)(nil),              // 9: scheduler.NodeMetrics
+	(*LatencyStats)(nil),             // 10: scheduler.LatencyStats
+	(*TaskStarted)(nil),              // 11: scheduler.TaskStarted
+	(*TaskAccepted)(nil),             // 12: scheduler.TaskAccepted
+	(*TaskLease)(nil),                // 13: scheduler.TaskLease
+	(*TaskResult)(nil),               // 14: scheduler.TaskResult
+	(*DrainRequest)(nil),             // 15: scheduler.DrainRequest
+	(*Deregister)(nil),               // 16: scheduler.Deregister
+	(*Pong)(nil),                     // 17: scheduler.Pong
+	(*MasterMessage)(nil),            // 18: scheduler.MasterMessage
+	(*CancelTask)(nil),               // 19: scheduler.CancelTask
+	(*Drain)(nil),                    // 20: scheduler.Drain
+	(*RegisterResponse)(nil),         // 21: scheduler.RegisterResponse
+	(*TaskAssignment)(nil),           // 22: scheduler.TaskAssignment
+	(*RetryPolicy)(nil),              // 23: scheduler.RetryPolicy
+	(*Placement)(nil),                // 24: scheduler.Placement
+	(*LabelExpression)(nil),          // 25: scheduler.LabelExpression
+	(*Ping)(nil),                     // 26: scheduler.Ping
+	(*SubmitTaskRequest)(nil),        // 27: scheduler.SubmitTaskRequest
+	(*SubmitTaskResponse)(nil),       // 28: scheduler.SubmitTaskResponse
+	(*SubmitBatchRequest)(nil),       // 29: scheduler.SubmitBatchRequest
+	(*SubmitBatchResponse)(nil),      // 30: scheduler.SubmitBatchResponse
+	(*SubmitBatchItem)(nil),          // 31: scheduler.SubmitBatchItem
+	(*TaskInfo)(nil),                 // 32: scheduler.TaskInfo
+	(*GetTaskStatusRequest)(nil),     // 33: scheduler.GetTaskStatusRequest
+	(*WaitForTaskRequest)(nil),       // 34: scheduler.WaitForTaskRequest
+	(*CancelTaskRequest)(nil),        // 35: scheduler.CancelTaskRequest
+	(*CancelTaskResponse)(nil),       // 36: scheduler.CancelTaskResponse
+	(*CancelTasksRequest)(nil),       // 37: scheduler.CancelTasksRequest
+	(*CancelTasksResponse)(nil),      // 38: scheduler.CancelTasksResponse
+	(*DrainWorkerRequest)(nil),       // 39: scheduler.DrainWorkerRequest
+	(*DrainWorkerResponse)(nil),      // 40: scheduler.DrainWorkerResponse
+	(*DeadLetter)(nil),               // 41: scheduler.DeadLetter
+	(*TaskFailure)(nil),              // 42: scheduler.TaskFailure
+	(*ListDeadLettersRequest)(nil),   // 43: scheduler.ListDeadLettersRequest
+	(*ListDeadLettersResponse)(nil),  // 44: scheduler.ListDeadLettersResponse
+	(*GetDeadLetterRequest)(nil),     // 45: scheduler.GetDeadLetterRequest
+	(*ReplayDeadLetterRequest)(nil),  // 46: scheduler.ReplayDeadLetterRequest
+	(*ReplayDeadLetterResponse)(nil), // 47: scheduler.ReplayDeadLetterResponse
+	nil,                              // 48: scheduler.RegisterRequest.LabelsEntry
+	nil,                              // 49: scheduler.Resources.NamedEntry
+	nil,                              // 50: scheduler.StatusUpdate.TaskLatencyEntry
+	nil,                              // 51: scheduler.TaskAssignment.LabelsEntry
+	nil,                              // 52: scheduler.Placement.NodeSelectorEntry
+	nil,                              // 53: scheduler.SubmitTaskRequest.LabelsEntry
+	nil,                              // 54: scheduler.TaskInfo.LabelsEntry
+	nil,                              // 55: scheduler.CancelTasksRequest.LabelsEntry
+	nil,                              // 56: scheduler.ListDeadLettersRequest.LabelsEntry
 }
 var file_proto_scheduler_proto_depIdxs = []int32{
-	1, // 0: scheduler.WorkerMessage.register_request:type_name -> scheduler.RegisterRequest
-	2, // 1: scheduler.WorkerMessage.status_update:type_name -> scheduler.StatusUpdate
-	4, // 2: scheduler.MasterMessage.register_response:type_name -> scheduler.RegisterResponse
-	5, // 3: scheduler.MasterMessage.task_assignment:type_name -> scheduler.TaskAssignment
-	0, // 4: scheduler.SchedulerService.Connect:input_type -> scheduler.WorkerMessage
-	3, // 5: scheduler.SchedulerService.Connect:output_type -> scheduler.MasterMessage
-	5, // [5:6] is the sub-list for method output_type
-	4, // [4:5] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	5,  // 0: scheduler.WorkerMessage.register_request:type_name -> scheduler.RegisterRequest
+	8,  // 1: scheduler.WorkerMessage.status_update:type_name -> scheduler.StatusUpdate
+	14, // 2: scheduler.WorkerMessage.task_result:type_name -> scheduler.TaskResult
+	17, // 3: scheduler.WorkerMessage.pong:type_name -> scheduler.Pong
+	11, // 4: scheduler.WorkerMessage.task_started:type_name -> scheduler.TaskStarted
+	15, // 5: scheduler.WorkerMessage.drain_request:type_name -> scheduler.DrainRequest
+	16, // 6: scheduler.WorkerMessage.deregister:type_name -> scheduler.Deregister
+	12, // 7: scheduler.WorkerMessage.task_accepted:type_name -> scheduler.TaskAccepted
+	48, // 8: scheduler.RegisterRequest.labels:type_name -> scheduler.RegisterRequest.LabelsEntry
+	7,  // 9: scheduler.RegisterRequest.capacity:type_name -> scheduler.Resources
+	6,  // 10: scheduler.RegisterRequest.in_flight:type_name -> scheduler.InFlightTask
+	22, // 11: scheduler.InFlightTask.task:type_name -> scheduler.TaskAssignment
+	49, // 12: scheduler.Resources.named:type_name -> scheduler.Resources.NamedEntry
+	9,  // 13: scheduler.StatusUpdate.metrics:type_name -> scheduler.NodeMetrics
+	10, // 14: scheduler.StatusUpdate.latency:type_name -> scheduler.LatencyStats
+	50, // 15: scheduler.StatusUpdate.task_latency:type_name -> scheduler.StatusUpdate.TaskLatencyEntry
+	13, // 16: scheduler.StatusUpdate.leases:type_name -> scheduler.TaskLease
+	0,  // 17: scheduler.TaskResult.status:type_name -> scheduler.TaskStatus
+	1,  // 18: scheduler.TaskResult.error_class:type_name -> scheduler.ErrorClass
+	21, // 19: scheduler.MasterMessage.register_response:type_name -> scheduler.RegisterResponse
+	22, // 20: scheduler.MasterMessage.task_assignment:type_name -> scheduler.TaskAssignment
+	26, // 21: scheduler.MasterMessage.ping:type_name -> scheduler.Ping
+	20, // 22: scheduler.MasterMessage.drain:type_name -> scheduler.Drain
+	19, // 23: scheduler.MasterMessage.cancel_task:type_name -> scheduler.CancelTask
+	24, // 24: scheduler.TaskAssignment.placement:type_name -> scheduler.Placement
+	7,  // 25: scheduler.TaskAssignment.requests:type_name -> scheduler.Resources
+	51, // 26: scheduler.TaskAssignment.labels:type_name -> scheduler.TaskAssignment.LabelsEntry
+	23, // 27: scheduler.TaskAssignment.retry_policy:type_name -> scheduler.RetryPolicy
+	1,  // 28: scheduler.RetryPolicy.retry_on:type_name -> scheduler.ErrorClass
+	52, // 29: scheduler.Placement.node_selector:type_name -> scheduler.Placement.NodeSelectorEntry
+	25, // 30: scheduler.Placement.affinity:type_name -> scheduler.LabelExpression
+	25, // 31: scheduler.Placement.anti_affinity:type_name -> scheduler.LabelExpression
+	2,  // 32: scheduler.LabelExpression.operator:type_name -> scheduler.LabelOperator
+	24, // 33: scheduler.SubmitTaskRequest.placement:type_name -> scheduler.Placement
+	7,  // 34: scheduler.SubmitTaskRequest.requests:type_name -> scheduler.Resources
+	53, // 35: scheduler.SubmitTaskRequest.labels:type_name -> scheduler.SubmitTaskRequest.LabelsEntry
+	23, // 36: scheduler.SubmitTaskRequest.retry_policy:type_name -> scheduler.RetryPolicy
+	3,  // 37: scheduler.SubmitTaskResponse.state:type_name -> scheduler.TaskState
+	27, // 38: scheduler.SubmitBatchRequest.tasks:type_name -> scheduler.SubmitTaskRequest
+	31, // 39: scheduler.SubmitBatchResponse.results:type_name -> scheduler.SubmitBatchItem
+	3,  // 40: scheduler.SubmitBatchItem.state:type_name -> scheduler.TaskState
+	3,  // 41: scheduler.TaskInfo.state:type_name -> scheduler.TaskState
+	54, // 42: scheduler.TaskInfo.labels:type_name -> scheduler.TaskInfo.LabelsEntry
+	32, // 43: scheduler.CancelTaskResponse.task:type_name -> scheduler.TaskInfo
+	55, // 44: scheduler.CancelTasksRequest.labels:type_name -> scheduler.CancelTasksRequest.LabelsEntry
+	32, // 45: scheduler.CancelTasksResponse.tasks:type_name -> scheduler.TaskInfo
+	32, // 46: scheduler.DeadLetter.task:type_name -> scheduler.TaskInfo
+	22, // 47: scheduler.DeadLetter.assignment:type_name -> scheduler.TaskAssignment
+	42, // 48: scheduler.DeadLetter.failures:type_name -> scheduler.TaskFailure
+	1,  // 49: scheduler.TaskFailure.error_class:type_name -> scheduler.ErrorClass
+	56, // 50: scheduler.ListDeadLettersRequest.labels:type_name -> scheduler.ListDeadLettersRequest.LabelsEntry
+	41, // 51: scheduler.ListDeadLettersResponse.dead_letters:type_name -> scheduler.DeadLetter
+	10, // 52: scheduler.StatusUpdate.TaskLatencyEntry.value:type_name -> scheduler.LatencyStats
+	4,  // 53: scheduler.SchedulerService.Connect:input_type -> scheduler.WorkerMessage
+	27, // 54: scheduler.TaskService.SubmitTask:input_type -> scheduler.SubmitTaskRequest
+	29, // 55: scheduler.TaskService.SubmitBatch:input_type -> scheduler.SubmitBatchRequest
+	33, // 56: scheduler.TaskService.GetTaskStatus:input_type -> scheduler.GetTaskStatusRequest
+	34, // 57: scheduler.TaskService.WaitForTask:input_type -> scheduler.WaitForTaskRequest
+	35, // 58: scheduler.TaskService.CancelTask:input_type -> scheduler.CancelTaskRequest
+	37, // 59: scheduler.TaskService.CancelTasks:input_type -> scheduler.CancelTasksRequest
+	39, // 60: scheduler.AdminService.DrainWorker:input_type -> scheduler.DrainWorkerRequest
+	43, // 61: scheduler.AdminService.ListDeadLetters:input_type -> scheduler.ListDeadLettersRequest
+	45, // 62: scheduler.AdminService.GetDeadLetter:input_type -> scheduler.GetDeadLetterRequest
+	46, // 63: scheduler.AdminService.ReplayDeadLetter:input_type -> scheduler.ReplayDeadLetterRequest
+	18, // 64: scheduler.SchedulerService.Connect:output_type -> scheduler.MasterMessage
+	28, // 65: scheduler.TaskService.SubmitTask:output_type -> scheduler.SubmitTaskResponse
+	30, // 66: scheduler.TaskService.SubmitBatch:output_type -> scheduler.SubmitBatchResponse
+	32, // 67: scheduler.TaskService.GetTaskStatus:output_type -> scheduler.TaskInfo
+	32, // 68: scheduler.TaskService.WaitForTask:output_type -> scheduler.TaskInfo
+	36, // 69: scheduler.TaskService.CancelTask:output_type -> scheduler.CancelTaskResponse
+	38, // 70: scheduler.TaskService.CancelTasks:output_type -> scheduler.CancelTasksResponse
+	40, // 71: scheduler.AdminService.DrainWorker:output_type -> scheduler.DrainWorkerResponse
+	44, // 72: scheduler.AdminService.ListDeadLetters:output_type -> scheduler.ListDeadLettersResponse
+	41, // 73: scheduler.AdminService.GetDeadLetter:output_type -> scheduler.DeadLetter
+	47, // 74: scheduler.AdminService.ReplayDeadLetter:output_type -> scheduler.ReplayDeadLetterResponse
+	64, // [64:75] is the sub-list for method output_type
+	53, // [53:64] is the sub-list for method input_type
+	53, // [53:53] is the sub-list for extension type_name
+	53, // [53:53] is the sub-list for extension extendee
+	0,  // [0:53] is the sub-list for field type_name
 }
 
 func init() { file_proto_scheduler_proto_init() }
@@ -475,23 +3785,33 @@ func file_proto_scheduler_proto_init() {
 	file_proto_scheduler_proto_msgTypes[0].OneofWrappers = []any{
 		(*WorkerMessage_RegisterRequest)(nil),
 		(*WorkerMessage_StatusUpdate)(nil),
+		(*WorkerMessage_TaskResult)(nil),
+		(*WorkerMessage_Pong)(nil),
+		(*WorkerMessage_TaskStarted)(nil),
+		(*WorkerMessage_DrainRequest)(nil),
+		(*WorkerMessage_Deregister)(nil),
+		(*WorkerMessage_TaskAccepted)(nil),
 	}
-	file_proto_scheduler_proto_msgTypes[3].OneofWrappers = []any{
+	file_proto_scheduler_proto_msgTypes[14].OneofWrappers = []any{
 		(*MasterMessage_RegisterResponse)(nil),
 		(*MasterMessage_TaskAssignment)(nil),
+		(*MasterMessage_Ping)(nil),
+		(*MasterMessage_Drain)(nil),
+		(*MasterMessage_CancelTask)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_scheduler_proto_rawDesc), len(file_proto_scheduler_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   6,
+			NumEnums:      4,
+			NumMessages:   53,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   3,
 		},
 		GoTypes:           file_proto_scheduler_proto_goTypes,
 		DependencyIndexes: file_proto_scheduler_proto_depIdxs,
+		EnumInfos:         file_proto_scheduler_proto_enumTypes,
 		MessageInfos:      file_proto_scheduler_proto_msgTypes,
 	}.Build()
 	File_proto_scheduler_proto = out.File